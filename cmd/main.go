@@ -1,8 +1,103 @@
 package main
 
-import "markoslav/internal/app"
+import (
+	"flag"
+	"fmt"
+	"io"
+	"markoslav/internal/app"
+	"markoslav/internal/transfer"
+	"net/http"
+	"os"
+	"time"
+)
+
+const usage = `Usage:
+  markoslav                     start the bot
+  markoslav import [flags] FILE import captions from FILE ("-" for stdin)
+  markoslav export [flags] FILE export captions to FILE ("-" for stdout)
+  markoslav healthcheck [flags] exit with a non-zero code unless the bot is ready
+
+Formats: jsonl, csv, txt (guessed from the file extension by default).
+`
 
 func main() {
-	app.New().
-		Run()
+	if len(os.Args) < 2 {
+		app.New().
+			Run()
+		return
+	}
+
+	switch os.Args[1] {
+	case "import":
+		flags := flag.NewFlagSet("import", flag.ExitOnError)
+		format := flags.String("format", "", "input format: jsonl, csv or txt")
+		approved := flags.Bool("approved", false, "mark imported captions as approved")
+		authorID := flags.Int64("author", 0, "author ID for captions without one")
+		flags.Parse(os.Args[2:])
+
+		path := flags.Arg(0)
+
+		app.NewTransfer().
+			Import(app.ImportOptions{
+				Path:     path,
+				Format:   resolveFormat(*format, path),
+				Approved: *approved,
+				AuthorID: *authorID,
+			})
+	case "export":
+		flags := flag.NewFlagSet("export", flag.ExitOnError)
+		format := flags.String("format", "", "output format: jsonl, csv or txt")
+		approvedOnly := flags.Bool("approved", false, "export only approved captions")
+		flags.Parse(os.Args[2:])
+
+		path := flags.Arg(0)
+
+		app.NewTransfer().
+			Export(app.ExportOptions{
+				Path:         path,
+				Format:       resolveFormat(*format, path),
+				ApprovedOnly: *approvedOnly,
+			})
+	case "healthcheck":
+		flags := flag.NewFlagSet("healthcheck", flag.ExitOnError)
+		url := flags.String("url", "http://127.0.0.1:8080/readyz", "readiness endpoint")
+		flags.Parse(os.Args[2:])
+
+		if err := healthcheck(*url); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func healthcheck(url string) error {
+	client := http.Client{Timeout: 10 * time.Second}
+
+	response, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("status code: %d: %s", response.StatusCode, body)
+	}
+
+	return nil
+}
+
+func resolveFormat(format string, path string) transfer.Format {
+	if format != "" {
+		return transfer.Format(format)
+	}
+
+	if path == "" || path == "-" {
+		return transfer.FormatJSONL
+	}
+
+	return transfer.FormatFromPath(path)
 }
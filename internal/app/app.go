@@ -2,31 +2,43 @@ package app
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"github.com/pressly/goose"
 	"log"
 	"markoslav/internal/bot"
 	"markoslav/internal/bot/handler"
 	"markoslav/internal/config"
+	"markoslav/internal/metrics"
+	"markoslav/internal/model"
+	"markoslav/internal/server"
+	serverhandler "markoslav/internal/server/handler"
 	"markoslav/internal/service"
 	"markoslav/internal/storage"
 	"markoslav/internal/usecase"
 	"markoslav/pkg/postgres"
+	"markoslav/pkg/ratelimit"
 	"os/signal"
+	"path/filepath"
 	"syscall"
+	"time"
+)
+
+const (
+	fontPath = "static/Lobster-Regular.ttf"
+	emojiDir = "static/emoji"
 )
 
 type App struct {
-	conf config.Config
-	bot  *bot.Bot
+	conf   config.Config
+	bot    *bot.Bot
+	server *server.Server
 }
 
 func New() *App {
-	conf := config.New()
-
 	return &App{
-		conf: conf,
-		bot:  bot.New(conf.Bot),
+		conf: config.New(),
 	}
 }
 
@@ -34,6 +46,112 @@ func (app *App) Run() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	app.bot = bot.New(app.conf.Bot)
+	app.server = server.New(app.conf.HTTP)
+
+	pgClient := app.connect(ctx)
+	defer pgClient.Close()
+
+	captionService := app.newCaptionService(pgClient)
+
+	imageService := metrics.NewImageService(app.newImageService())
+
+	moderationEventStorage := metrics.NewModerationEventStorage(storage.NewModerationEventStorage(pgClient))
+	moderationEventService := service.NewModerationEventService(moderationEventStorage)
+
+	adminStorage := metrics.NewAdminStorage(storage.NewAdminStorage(pgClient))
+	adminService := service.NewAdminService(adminStorage, app.conf.Bot.AdminList, app.conf.Bot.AdminCacheTTL)
+
+	chatMemberService := service.NewChatMemberService(app.bot.API, app.conf.Bot.AdminCacheTTL)
+
+	banStorage := metrics.NewBanStorage(storage.NewBanStorage(pgClient))
+	banService := service.NewBanService(banStorage)
+
+	downloadService := service.NewCachedDownloadService(
+		metrics.NewDownloadService(service.NewDownloadService(service.DownloadConfig{
+			Timeout: app.conf.Download.Timeout, Retries: app.conf.Download.Retries,
+			RetryDelay: app.conf.Download.RetryDelay, MaxBytes: app.conf.Download.MaxBytes,
+		})),
+		app.conf.Download.CacheMaxBytes,
+	)
+
+	renderedImageStorage := metrics.NewRenderedImageStorage(storage.NewRenderedImageStorage(pgClient))
+	renderedImageService := service.NewRenderedImageService(renderedImageStorage, renderFingerprint(app.conf.Image, emojiDir))
+
+	encoderService := app.newEncoderService()
+
+	chatSettingsStorage := metrics.NewChatSettingsStorage(storage.NewChatSettingsStorage(pgClient))
+	chatSettingsService := service.NewChatSettingsService(chatSettingsStorage)
+
+	suggestLimiter := ratelimit.NewWindow(app.conf.Bot.SuggestLimit, app.conf.Bot.SuggestLimitPeriod)
+	customLimiter := ratelimit.NewWindow(app.conf.Bot.CustomCaptionLimit, app.conf.Bot.CustomCaptionLimitPeriod)
+
+	captionUsecase := usecase.NewCaptionUsecase(
+		captionService, moderationEventService, banService, imageService, suggestLimiter, customLimiter,
+	)
+	moderationEventUsecase := usecase.NewModerationEventUsecase(moderationEventService)
+	adminUsecase := usecase.NewAdminUsecase(adminService)
+	permissionUsecase := usecase.NewPermissionUsecase(adminService, chatMemberService)
+	banUsecase := usecase.NewBanUsecase(banService, adminService)
+	downloadUsecase := usecase.NewDownloadUsecase(downloadService)
+	renderedImageUsecase := usecase.NewRenderedImageUsecase(renderedImageService)
+	imageUsecase := usecase.NewImageUsecase(encoderService)
+	chatSettingsUsecase := usecase.NewChatSettingsUsecase(chatSettingsService)
+
+	captionHandler := handler.NewCaptionHandler(
+		app.bot.Sender, captionUsecase, permissionUsecase, downloadUsecase, renderedImageUsecase, imageUsecase,
+		chatSettingsUsecase,
+	)
+	moderationEventHandler := handler.NewModerationEventHandler(app.bot.Sender, moderationEventUsecase, permissionUsecase)
+	adminHandler := handler.NewAdminHandler(app.bot.Sender, adminUsecase, permissionUsecase)
+	banHandler := handler.NewBanHandler(app.bot.Sender, banUsecase, permissionUsecase)
+	chatSettingsHandler := handler.NewChatSettingsHandler(app.bot.Sender, chatSettingsUsecase, permissionUsecase)
+
+	updateOffsetStorage := metrics.NewUpdateOffsetStorage(storage.NewUpdateOffsetStorage(pgClient))
+
+	go app.bot.Handle(captionHandler, moderationEventHandler, adminHandler, banHandler, chatSettingsHandler).
+		WithOffsetStorage(updateOffsetStorage).
+		Run()
+
+	migrationVersion, err := latestMigrationVersion("migration")
+	if err != nil {
+		log.Fatalf("migration error: %s", err)
+	}
+
+	healthService := service.NewHealthService(map[string]service.HealthCheck{
+		"postgres":   service.PostgresHealthCheck(pgClient),
+		"telegram":   service.TelegramHealthCheck(app.bot.API),
+		"migrations": service.MigrationsHealthCheck(storage.NewMigrationStorage(pgClient), migrationVersion),
+	}, 5*time.Second)
+
+	captionServerHandler := serverhandler.NewCaptionHandler(captionUsecase, adminUsecase, app.conf.HTTP.Token)
+	healthServerHandler := serverhandler.NewHealthHandler(healthService)
+	metricsServerHandler := serverhandler.NewMetricsHandler()
+
+	go app.server.Handle(captionServerHandler, healthServerHandler, metricsServerHandler).
+		Run()
+
+	<-ctx.Done()
+	fmt.Println("graceful shutdown")
+
+	app.shutdown()
+}
+
+// shutdown stops the bot and the HTTP server, giving in-flight work APP_SHUTDOWN_TIMEOUT to finish.
+func (app *App) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), app.conf.App.ShutdownTimeout)
+	defer cancel()
+
+	if err := app.bot.Shutdown(ctx); err != nil {
+		log.Printf("bot shutdown: %s", err)
+	}
+
+	if err := app.server.Shutdown(ctx); err != nil {
+		log.Printf("http server shutdown: %s", err)
+	}
+}
+
+func (app *App) connect(ctx context.Context) postgres.Client {
 	pgConfig := postgres.Config{
 		Host: app.conf.Postgres.Host, Port: app.conf.Postgres.Port, DB: app.conf.Postgres.DB,
 		User: app.conf.Postgres.User, Password: app.conf.Postgres.Password,
@@ -47,22 +165,67 @@ func (app *App) Run() {
 		log.Fatalf("migration error: %s", err)
 	}
 
-	captionStorage := storage.NewCaptionStorage(pgClient)
-	captionService := service.NewCaptionService(captionStorage)
+	return pgClient
+}
+
+func (app *App) newEncoderService() service.EncoderService {
+	format := model.ImageFormat(app.conf.Image.Format)
+	if !format.Valid() {
+		log.Fatalf("unknown image format %q, expected jpeg or png", app.conf.Image.Format)
+	}
 
-	imageService := service.NewImageService("static/Lobster-Regular.ttf")
+	return service.NewEncoderService(service.EncoderConfig{Format: format, JPEGQuality: app.conf.Image.JPEGQuality})
+}
 
-	captionUsecase := usecase.NewCaptionUsecase(captionService, imageService)
+func (app *App) newImageService() service.ImageService {
+	placement := service.Placement(app.conf.Image.Placement)
+	if !placement.Valid() {
+		log.Fatalf("unknown caption placement %q", app.conf.Image.Placement)
+	}
 
-	captionHandler := handler.NewCaptionHandler(app.bot.API, captionUsecase, app.conf.Bot.AdminList)
+	textStyle := model.TextStyle(app.conf.Image.TextStyle)
+	if !textStyle.Valid() {
+		log.Fatalf("unknown text style %q", app.conf.Image.TextStyle)
+	}
 
-	go app.bot.Handle(captionHandler).
-		Run()
+	return service.NewImageService(service.ImageConfig{
+		FontPath: fontPath, EmojiDir: emojiDir,
+		MaxDimension: app.conf.Image.MaxDimension, Placement: placement,
+		TextStyle: textStyle, BackdropOpacity: app.conf.Image.BackdropOpacity,
+	})
+}
 
-	select {
-	case <-ctx.Done():
-		fmt.Println("graceful shutdown")
+func (app *App) newCaptionService(pgClient postgres.Client) service.CaptionService {
+	captionValidator, err := service.NewCaptionValidator(service.CaptionValidationConfig{
+		MinLength: app.conf.Caption.MinLength, MaxLength: app.conf.Caption.MaxLength, MaxLines: app.conf.Caption.MaxLines,
+		AllowLinks: app.conf.Caption.AllowLinks, AllowMentions: app.conf.Caption.AllowMentions,
+		BlocklistPath: app.conf.Caption.BlocklistPath,
+	})
+	if err != nil {
+		log.Fatalf("caption validator: %s", err)
 	}
+
+	captionStorage := metrics.NewCaptionStorage(storage.NewCaptionStorage(pgClient))
+	renderedImageStorage := metrics.NewRenderedImageStorage(storage.NewRenderedImageStorage(pgClient))
+
+	return service.NewCaptionService(
+		captionStorage, renderedImageStorage, captionValidator, app.conf.Caption.SimilarityThreshold,
+	)
+}
+
+// renderFingerprint is a short hash of the image settings and emoji images that affect how captioned
+// photos look, so photos rendered without emoji are not reused once the images are added.
+func renderFingerprint(conf config.Image, emojiDir string) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%+v", conf)
+
+	// Missing or unreadable emoji images leave no names, the same as an empty set.
+	names, _ := filepath.Glob(filepath.Join(emojiDir, "*.png"))
+	for _, name := range names {
+		fmt.Fprintf(hash, "\n%s", filepath.Base(name))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)[:4])
 }
 
 func migrate(command string, dir string, dbstring string) error {
@@ -78,3 +241,17 @@ func migrate(command string, dir string, dbstring string) error {
 
 	return nil
 }
+
+func latestMigrationVersion(dir string) (int64, error) {
+	migrations, err := goose.CollectMigrations(dir, 0, goose.MaxVersion)
+	if err != nil {
+		return 0, err
+	}
+
+	last, err := migrations.Last()
+	if err != nil {
+		return 0, err
+	}
+
+	return last.Version, nil
+}
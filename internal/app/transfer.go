@@ -0,0 +1,166 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"markoslav/internal/config"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/service"
+	"markoslav/internal/storage"
+	"markoslav/internal/transfer"
+	"markoslav/internal/usecase"
+	"markoslav/pkg/filter"
+	"markoslav/pkg/ratelimit"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const exportBatchSize = 500
+
+type ImportOptions struct {
+	Path     string
+	Format   transfer.Format
+	Approved bool
+	AuthorID int64
+}
+
+type ExportOptions struct {
+	Path         string
+	Format       transfer.Format
+	ApprovedOnly bool
+}
+
+// NewTransfer creates an app for the import and export commands, which need no bot settings.
+func NewTransfer() *App {
+	conf := config.NewTransfer()
+
+	return &App{
+		conf: config.Config{Postgres: conf.Postgres, Caption: conf.Caption, Image: conf.Image},
+	}
+}
+
+func (app *App) Import(options ImportOptions) {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	var input io.Reader = os.Stdin
+	if options.Path != "" && options.Path != "-" {
+		file, err := os.Open(options.Path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		input = file
+	}
+
+	records, err := transfer.ReadCaptions(input, options.Format)
+	if err != nil {
+		log.Fatalf("read captions: %s", err)
+	}
+
+	invalid := 0
+
+	requests := make([]dto.CreateCaption, 0, len(records))
+	for _, record := range records {
+		if record.Invalid {
+			invalid++
+			continue
+		}
+
+		authorID := record.AuthorID
+		if authorID == 0 {
+			authorID = options.AuthorID
+		}
+
+		requests = append(requests, dto.CreateCaption{
+			Text:     record.Text,
+			AuthorID: authorID,
+			Approved: record.Approved || options.Approved,
+		})
+	}
+
+	captionUsecase := app.newTransferCaptionUsecase(ctx)
+
+	result, err := captionUsecase.Import(ctx, requests)
+	result.Invalid += invalid
+
+	// Summaries go to stderr, as stdout may carry exported captions.
+	fmt.Fprintf(os.Stderr, "inserted: %d, duplicates: %d, invalid: %d\n", result.Inserted, result.Duplicates, result.Invalid)
+
+	if err != nil {
+		log.Fatalf("import captions: %s", err)
+	}
+}
+
+func (app *App) Export(options ExportOptions) {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	var output io.Writer = os.Stdout
+	if options.Path != "" && options.Path != "-" {
+		file, err := os.Create(options.Path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		output = file
+	}
+
+	writer, err := transfer.NewCaptionWriter(output, options.Format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	captionUsecase := app.newTransferCaptionUsecase(ctx)
+
+	filterOptions := filter.NewOptions()
+	if options.ApprovedOnly {
+		filterOptions.Add("approved", true, filter.OperatorEq)
+	}
+
+	exported := 0
+	for offset := 0; ; offset += exportBatchSize {
+		var captions []model.Caption
+		captions, err = captionUsecase.Select(ctx, exportBatchSize, offset, filterOptions)
+		if err != nil {
+			log.Fatalf("select captions: %s", err)
+		}
+
+		for _, caption := range captions {
+			if err = writer.Write(caption); err != nil {
+				log.Fatalf("write caption: %s", err)
+			}
+		}
+
+		exported += len(captions)
+
+		if len(captions) < exportBatchSize {
+			break
+		}
+	}
+
+	if err = writer.Flush(); err != nil {
+		log.Fatalf("write captions: %s", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "exported: %d\n", exported)
+}
+
+func (app *App) newTransferCaptionUsecase(ctx context.Context) usecase.CaptionUsecase {
+	pgClient := app.connect(ctx)
+
+	return usecase.NewCaptionUsecase(
+		app.newCaptionService(pgClient),
+		service.NewModerationEventService(storage.NewModerationEventStorage(pgClient)),
+		service.NewBanService(storage.NewBanStorage(pgClient)),
+		app.newImageService(),
+		ratelimit.NewWindow(0, 0),
+		ratelimit.NewWindow(0, 0),
+	)
+}
@@ -0,0 +1,191 @@
+package bot
+
+import (
+	"context"
+	"github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"markoslav/internal/config"
+	"markoslav/internal/storage"
+	"sync"
+	"time"
+)
+
+// backlogPolicy decides which stale updates, e.g. the ones piled up while the bot was down, are skipped.
+type backlogPolicy struct {
+	maxAge       time.Duration
+	keepCommands bool
+	keepPrivate  bool
+}
+
+func newBacklogPolicy(conf config.Backlog) backlogPolicy {
+	return backlogPolicy{
+		maxAge:       conf.MaxAge,
+		keepCommands: conf.KeepCommands,
+		keepPrivate:  conf.KeepPrivate,
+	}
+}
+
+// Skip reports whether the update is older than the max age and is not kept by the policy.
+// Updates without a message, e.g. callback queries, are never skipped.
+func (policy backlogPolicy) Skip(update tgbotapi.Update, now time.Time) bool {
+	if policy.maxAge <= 0 {
+		return false
+	}
+
+	message := updateMessage(update)
+	if message == nil {
+		return false
+	}
+
+	date := message.Date
+	if message.EditDate != 0 {
+		date = message.EditDate
+	}
+
+	if now.Sub(time.Unix(int64(date), 0)) <= policy.maxAge {
+		return false
+	}
+
+	if policy.keepCommands && message.IsCommand() {
+		return false
+	}
+
+	if policy.keepPrivate && message.Chat != nil && message.Chat.IsPrivate() {
+		return false
+	}
+
+	return true
+}
+
+func updateMessage(update tgbotapi.Update) *tgbotapi.Message {
+	switch {
+	case update.Message != nil:
+		return update.Message
+	case update.EditedMessage != nil:
+		return update.EditedMessage
+	case update.ChannelPost != nil:
+		return update.ChannelPost
+	case update.EditedChannelPost != nil:
+		return update.EditedChannelPost
+	}
+
+	return nil
+}
+
+// offsetMaxAge is how long an update ID is trusted. Telegram keeps undelivered updates for a day
+// and starts update IDs from a random number after a week without updates, so older offsets
+// can only make the bot drop new updates as already seen.
+const offsetMaxAge = 24 * time.Hour
+
+// offsetTracker keeps track of updates being processed by the workers and persists
+// the highest update ID below which every update has been handled.
+type offsetTracker struct {
+	storage storage.UpdateOffsetStorage
+
+	mu      sync.Mutex
+	pending map[int]struct{}
+	last    int
+	saved   int
+	// active is when the last update was seen or the offset was saved.
+	active time.Time
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{pending: make(map[int]struct{})}
+}
+
+// Load returns the last persisted update ID.
+func (tracker *offsetTracker) Load(ctx context.Context) (int, error) {
+	if tracker.storage == nil {
+		return 0, nil
+	}
+
+	offset, err := tracker.storage.Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if offset.LastUpdateID != 0 && time.Since(offset.UpdatedAt) > offsetMaxAge {
+		log.Printf("update offset %d is stale since %s, starting over", offset.LastUpdateID, offset.UpdatedAt)
+		return 0, nil
+	}
+
+	tracker.mu.Lock()
+	tracker.last, tracker.saved = offset.LastUpdateID, offset.LastUpdateID
+	tracker.active = offset.UpdatedAt
+	tracker.mu.Unlock()
+
+	return offset.LastUpdateID, nil
+}
+
+// Seen reports whether the update was already processed before the last restart.
+// After a long pause the update IDs may have been reset, so the old offset is forgotten.
+func (tracker *offsetTracker) Seen(updateID int) bool {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	now := time.Now()
+	if updateID <= tracker.saved && now.Sub(tracker.active) > offsetMaxAge {
+		log.Printf("update ID %d is below the stale offset %d, starting over", updateID, tracker.saved)
+		tracker.last, tracker.saved = 0, 0
+	}
+	tracker.active = now
+
+	return updateID <= tracker.saved
+}
+
+// Start marks the update as being processed.
+func (tracker *offsetTracker) Start(updateID int) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	tracker.pending[updateID] = struct{}{}
+	if updateID > tracker.last {
+		tracker.last = updateID
+	}
+}
+
+// Done marks the update as processed, dropped or skipped.
+func (tracker *offsetTracker) Done(updateID int) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	delete(tracker.pending, updateID)
+	if updateID > tracker.last {
+		tracker.last = updateID
+	}
+}
+
+// Flush persists the offset if it moved since the last save.
+func (tracker *offsetTracker) Flush(ctx context.Context) error {
+	if tracker.storage == nil {
+		return nil
+	}
+
+	tracker.mu.Lock()
+	offset := tracker.last
+	for updateID := range tracker.pending {
+		if updateID-1 < offset {
+			offset = updateID - 1
+		}
+	}
+	saved := tracker.saved
+	tracker.mu.Unlock()
+
+	if offset <= saved {
+		return nil
+	}
+
+	if err := tracker.storage.Save(ctx, offset); err != nil {
+		return err
+	}
+
+	tracker.mu.Lock()
+	if offset > tracker.saved {
+		tracker.saved = offset
+	}
+	tracker.active = time.Now()
+	tracker.mu.Unlock()
+
+	return nil
+}
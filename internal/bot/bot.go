@@ -1,18 +1,42 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"github.com/and3rson/telemux/v2"
 	"github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"log"
-	"markoslav/internal/bot/handler"
+	"markoslav/internal/bot/botctx"
 	"markoslav/internal/config"
+	"markoslav/internal/metrics"
+	"markoslav/internal/storage"
+	"reflect"
+	"strings"
 	"time"
 )
 
+const (
+	// offsetFlushInterval is how often the last processed update ID is saved while the bot is running.
+	offsetFlushInterval = 5 * time.Second
+	// offsetFlushTimeout limits the final save, which must not use the context cancelled by Shutdown.
+	offsetFlushTimeout = 5 * time.Second
+)
+
 type Bot struct {
-	API *tgbotapi.BotAPI
-	Mux *telemux.Mux
+	API    *tgbotapi.BotAPI
+	Sender *Sender
+	Mux    *telemux.Mux
+
+	webhook *webhook
+	pool    *pool
+	backlog backlogPolicy
+	offsets *offsetTracker
+
+	// ctx is passed to handlers and cancelled if they outlive the shutdown deadline.
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	done   chan struct{}
 }
 
 func New(conf config.Bot) *Bot {
@@ -25,44 +49,216 @@ func New(conf config.Bot) *Bot {
 		api.Debug = true
 	}
 
-	return &Bot{
-		API: api,
-		Mux: telemux.NewMux(),
+	api.Client = metrics.NewTelegramClient(api.Client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	bot := &Bot{
+		API:    api,
+		Sender: NewSender(api, conf.Send),
+		Mux:    telemux.NewMux(),
+		ctx:    ctx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+
+		backlog: newBacklogPolicy(conf.Backlog),
+		offsets: newOffsetTracker(),
+	}
+	bot.pool = newPool(conf.Workers, conf.QueueSize, conf.QueueTimeout, bot.dispatch)
+
+	switch conf.Mode {
+	case ModePolling:
+	case ModeWebhook:
+		if bot.webhook, err = newWebhook(api, conf.Webhook); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("unknown bot mode %q", conf.Mode)
+	}
+
+	return bot
+}
+
+type Handler interface {
+	Register(mux *telemux.Mux)
+}
+
+// instrumentedMux records metrics for updates processed by one of the registered handlers.
+type instrumentedMux struct {
+	name string
+	mux  *telemux.Mux
+}
+
+func (processor *instrumentedMux) Process(update *telemux.Update) bool {
+	start := time.Now()
+
+	if !processor.mux.Process(update) {
+		return false
+	}
+
+	metrics.UpdatesDispatched.WithLabelValues(processor.name).Inc()
+	metrics.UpdateDuration.WithLabelValues(processor.name).Observe(time.Since(start).Seconds())
+
+	return true
+}
+
+func (bot *Bot) Handle(handlers ...Handler) *Bot {
+	for _, handler := range handlers {
+		mux := telemux.NewMux()
+		handler.Register(mux)
+
+		bot.Mux.Processors = append(bot.Mux.Processors, &instrumentedMux{name: handlerName(handler), mux: mux})
 	}
+
+	return bot
 }
 
-func (bot *Bot) Handle(captionHandler *handler.CaptionHandler) *Bot {
-	captionHandler.Register(bot.Mux)
+// WithOffsetStorage makes the bot persist the last processed update ID and resume from it after a restart.
+func (bot *Bot) WithOffsetStorage(storage storage.UpdateOffsetStorage) *Bot {
+	bot.offsets.storage = storage
 
 	return bot
 }
 
 func (bot *Bot) Run() {
-	updateConfig := tgbotapi.NewUpdate(0)
-	updateConfig.Timeout = 60
-
-	updatesChannel := bot.API.GetUpdatesChan(updateConfig)
+	defer close(bot.done)
 
-	fmt.Println("clear updates")
+	offset, err := bot.offsets.Load(bot.ctx)
+	if err != nil {
+		log.Printf("load update offset: %s", err)
+	}
 
-	bot.clearUpdatesChannel(updatesChannel)
+	updatesChannel, err := bot.updates(offset)
+	if err != nil {
+		log.Fatalf("bot: %s", err)
+	}
 
 	fmt.Println("bot started")
 
-	for update := range updatesChannel {
-		bot.Mux.Dispatch(bot.API, update)
+	bot.pool.Start()
+
+	go bot.flushOffsets()
+
+	bot.receive(updatesChannel)
+
+	bot.pool.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), offsetFlushTimeout)
+	defer cancel()
+
+	if err = bot.offsets.Flush(ctx); err != nil {
+		log.Printf("save update offset: %s", err)
 	}
 }
 
-func (bot *Bot) clearUpdatesChannel(updatesChannel tgbotapi.UpdatesChannel) {
-	timer := time.NewTimer(5 * time.Second)
+func (bot *Bot) receive(updatesChannel tgbotapi.UpdatesChannel) {
+	for {
+		select {
+		case update, ok := <-updatesChannel:
+			if !ok {
+				return
+			}
+
+			bot.submit(update)
+		case <-bot.stop:
+			return
+		}
+	}
+}
+
+func (bot *Bot) submit(update tgbotapi.Update) {
+	if bot.offsets.Seen(update.UpdateID) {
+		return
+	}
+
+	if bot.backlog.Skip(update, time.Now()) {
+		metrics.UpdatesSkipped.Inc()
+		bot.offsets.Done(update.UpdateID)
+		return
+	}
+
+	bot.offsets.Start(update.UpdateID)
+
+	if !bot.pool.Submit(update) {
+		bot.offsets.Done(update.UpdateID)
+	}
+}
+
+func (bot *Bot) flushOffsets() {
+	ticker := time.NewTicker(offsetFlushInterval)
+	defer ticker.Stop()
 
 	for {
 		select {
-		case <-updatesChannel:
-			timer.Reset(5 * time.Second)
-		case <-timer.C:
+		case <-ticker.C:
+			if err := bot.offsets.Flush(bot.ctx); err != nil {
+				log.Printf("save update offset: %s", err)
+			}
+		case <-bot.stop:
 			return
 		}
 	}
 }
+
+func (bot *Bot) dispatch(update tgbotapi.Update) {
+	defer bot.offsets.Done(update.UpdateID)
+
+	processed := bot.Mux.Process(&telemux.Update{
+		Update:  update,
+		Bot:     bot.API,
+		Context: telemux.Map{botctx.Key: bot.ctx},
+	})
+	if !processed {
+		metrics.UpdatesDispatched.WithLabelValues("none").Inc()
+	}
+}
+
+// Shutdown stops receiving updates and waits for the queued ones to be processed and
+// their replies sent. In webhook mode the webhook is also removed from Telegram. When ctx
+// expires first, the context of the running handlers is cancelled.
+func (bot *Bot) Shutdown(ctx context.Context) error {
+	if bot.webhook != nil {
+		bot.webhook.Stop(ctx)
+	} else {
+		bot.API.StopReceivingUpdates()
+	}
+
+	close(bot.stop)
+
+	// Handlers may still be sending replies, so the sender is stopped once they are done.
+	select {
+	case <-bot.done:
+	case <-ctx.Done():
+	}
+
+	err := bot.Sender.Stop(ctx)
+	bot.cancel()
+
+	if err == nil {
+		err = ctx.Err()
+	}
+
+	return err
+}
+
+func (bot *Bot) updates(offset int) (tgbotapi.UpdatesChannel, error) {
+	if bot.webhook != nil {
+		return bot.webhook.Start()
+	}
+
+	updateConfig := tgbotapi.NewUpdate(offset + 1)
+	updateConfig.Timeout = 60
+
+	return bot.API.GetUpdatesChan(updateConfig), nil
+}
+
+// handlerName turns *handler.CaptionHandler into "caption".
+func handlerName(handler Handler) string {
+	t := reflect.TypeOf(handler)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return strings.ToLower(strings.TrimSuffix(t.Name(), "Handler"))
+}
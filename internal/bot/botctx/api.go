@@ -0,0 +1,46 @@
+package botctx
+
+import (
+	"errors"
+	"github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// API is the part of tgbotapi.BotAPI used by handlers, extended with queued sending.
+type API interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Post(c tgbotapi.Chattable, done func(tgbotapi.Message, error))
+	GetFileDirectURL(fileID string) (string, error)
+	// Go runs f in the background and makes shutdown wait for it, e.g. for work after a message is sent.
+	Go(f func())
+}
+
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+)
+
+// ErrSenderStopped is returned for messages sent after Stop or still queued when it gave up.
+var ErrSenderStopped = errors.New("sender stopped")
+
+// prioritized overrides the priority the sender would pick for the message.
+type prioritized struct {
+	tgbotapi.Chattable
+	priority Priority
+}
+
+// WithPriority makes the sender queue c with the given priority, e.g. replies to admins in groups.
+func WithPriority(c tgbotapi.Chattable, priority Priority) tgbotapi.Chattable {
+	return prioritized{Chattable: c, priority: priority}
+}
+
+// Unwrap returns the message wrapped by WithPriority and its priority, ok is false for other messages.
+func Unwrap(c tgbotapi.Chattable) (message tgbotapi.Chattable, priority Priority, ok bool) {
+	if p, ok := c.(prioritized); ok {
+		return p.Chattable, p.priority, true
+	}
+
+	return c, 0, false
+}
@@ -0,0 +1,19 @@
+package botctx
+
+import (
+	"context"
+	"github.com/and3rson/telemux/v2"
+)
+
+// Key is the telemux.Update context entry holding the context.Context of the update.
+const Key = "ctx"
+
+// Context returns the context the update is processed with. It is cancelled when
+// the bot fails to finish in-flight updates before the shutdown deadline.
+func Context(update *telemux.Update) context.Context {
+	if ctx, ok := update.Context[Key].(context.Context); ok {
+		return ctx
+	}
+
+	return context.Background()
+}
@@ -0,0 +1,45 @@
+package botctx
+
+import (
+	"github.com/and3rson/telemux/v2"
+	"sync"
+)
+
+// LocalPersistence is telemux.LocalPersistence guarded by a mutex, so conversations
+// can be used by several workers at once.
+type LocalPersistence struct {
+	mu          sync.Mutex
+	persistence *telemux.LocalPersistence
+}
+
+func NewLocalPersistence() *LocalPersistence {
+	return &LocalPersistence{persistence: telemux.NewLocalPersistence()}
+}
+
+func (persistence *LocalPersistence) GetState(pk telemux.PersistenceKey) string {
+	persistence.mu.Lock()
+	defer persistence.mu.Unlock()
+
+	return persistence.persistence.GetState(pk)
+}
+
+func (persistence *LocalPersistence) SetState(pk telemux.PersistenceKey, state string) {
+	persistence.mu.Lock()
+	defer persistence.mu.Unlock()
+
+	persistence.persistence.SetState(pk, state)
+}
+
+func (persistence *LocalPersistence) GetData(pk telemux.PersistenceKey) telemux.Data {
+	persistence.mu.Lock()
+	defer persistence.mu.Unlock()
+
+	return persistence.persistence.GetData(pk)
+}
+
+func (persistence *LocalPersistence) SetData(pk telemux.PersistenceKey, data telemux.Data) {
+	persistence.mu.Lock()
+	defer persistence.mu.Unlock()
+
+	persistence.persistence.SetData(pk, data)
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/and3rson/telemux/v2"
+	"github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"markoslav/internal/bot/botctx"
+	"markoslav/internal/bot/template"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/usecase"
+	"markoslav/pkg/apperror"
+	"strconv"
+)
+
+const AdminUsageMessageText = `
+Использование:
+
+/admin list - список администраторов
+/admin add <id> [owner|moderator] - добавить администратора
+/admin remove <id> - удалить администратора
+`
+
+var AdminRoleNames = map[model.AdminRole]string{
+	model.AdminRoleOwner:     "владелец",
+	model.AdminRoleModerator: "модератор",
+}
+
+type AdminHandler struct {
+	api               botctx.API
+	adminUsecase      usecase.AdminUsecase
+	permissionUsecase usecase.PermissionUsecase
+}
+
+func NewAdminHandler(
+	api botctx.API,
+	adminUsecase usecase.AdminUsecase,
+	permissionUsecase usecase.PermissionUsecase,
+) *AdminHandler {
+	return &AdminHandler{api: api, adminUsecase: adminUsecase, permissionUsecase: permissionUsecase}
+}
+
+func (handler *AdminHandler) Register(mux *telemux.Mux) {
+	mux.AddHandler(
+		telemux.NewCommandHandler(
+			"admin",
+			telemux.And(telemux.IsPrivate(), hasPermission(handler.permissionUsecase, model.PermissionManageAdmins)),
+			func(update *telemux.Update) {
+				args := update.Context["args"].([]string)
+				chat := update.EffectiveChat()
+
+				var text string
+				switch {
+				case len(args) == 1 && args[0] == "list":
+					text = handler.list(botctx.Context(update))
+				case len(args) >= 2 && args[0] == "add":
+					text = handler.add(botctx.Context(update), update.EffectiveUser().ID, args[1:])
+				case len(args) == 2 && args[0] == "remove":
+					text = handler.remove(botctx.Context(update), args[1])
+				default:
+					text = AdminUsageMessageText
+				}
+
+				if _, err := handler.api.Send(tgbotapi.NewMessage(chat.ID, text)); err != nil {
+					log.Println(err)
+				}
+			},
+		),
+	)
+}
+
+func (handler *AdminHandler) list(ctx context.Context) string {
+	admins, err := handler.adminUsecase.Select(ctx)
+	if err != nil {
+		return errorText("select admins", err, nil)
+	}
+
+	items := make([]map[string]any, 0, len(admins))
+	for _, admin := range admins {
+		items = append(items, map[string]any{
+			"user_id": admin.UserID,
+			"role":    AdminRoleNames[admin.Role],
+		})
+	}
+
+	buffer := new(bytes.Buffer)
+	if err = template.AdminList.Execute(buffer, map[string]any{"admins": items}); err != nil {
+		return UnknownErrorMessageText
+	}
+
+	return buffer.String()
+}
+
+func (handler *AdminHandler) add(ctx context.Context, addedBy int64, args []string) string {
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return AdminUsageMessageText
+	}
+
+	role := model.AdminRoleModerator
+	if len(args) > 1 {
+		role = model.AdminRole(args[1])
+	}
+
+	_, err = handler.adminUsecase.Add(ctx, dto.AddAdmin{
+		UserID:  userID,
+		Role:    role,
+		AddedBy: addedBy,
+	})
+	if err != nil {
+		return errorText("add admin", err, map[apperror.Code]string{
+			apperror.BadRequest.Code: AdminUsageMessageText,
+			apperror.Forbidden.Code:  "Нельзя изменить владельца, заданного в конфигурации.",
+		})
+	}
+
+	return fmt.Sprintf("Пользователь %d назначен: %s.", userID, AdminRoleNames[role])
+}
+
+func (handler *AdminHandler) remove(ctx context.Context, arg string) string {
+	userID, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return AdminUsageMessageText
+	}
+
+	err = handler.adminUsecase.Remove(ctx, userID)
+	if err != nil {
+		return errorText("remove admin", err, map[apperror.Code]string{
+			apperror.NotFound.Code:  "Пользователь не является администратором.",
+			apperror.Forbidden.Code: "Нельзя удалить владельца, заданного в конфигурации.",
+		})
+	}
+
+	return fmt.Sprintf("Пользователь %d больше не администратор.", userID)
+}
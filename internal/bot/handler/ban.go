@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/and3rson/telemux/v2"
+	"github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"markoslav/internal/bot/botctx"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/usecase"
+	"markoslav/pkg/apperror"
+	"strconv"
+	"strings"
+)
+
+const (
+	BanUsageMessageText   = "Использование: /ban <id> [причина]"
+	UnbanUsageMessageText = "Использование: /unban <id>"
+)
+
+type BanHandler struct {
+	api               botctx.API
+	banUsecase        usecase.BanUsecase
+	permissionUsecase usecase.PermissionUsecase
+}
+
+func NewBanHandler(
+	api botctx.API,
+	banUsecase usecase.BanUsecase,
+	permissionUsecase usecase.PermissionUsecase,
+) *BanHandler {
+	return &BanHandler{api: api, banUsecase: banUsecase, permissionUsecase: permissionUsecase}
+}
+
+func (handler *BanHandler) Register(mux *telemux.Mux) {
+	mux.AddHandler(
+		telemux.NewCommandHandler(
+			"ban",
+			telemux.And(telemux.IsPrivate(), hasPermission(handler.permissionUsecase, model.PermissionBan)),
+			func(update *telemux.Update) {
+				args := update.Context["args"].([]string)
+				reply := tgbotapi.NewMessage(update.EffectiveChat().ID, BanUsageMessageText)
+
+				if len(args) > 0 {
+					reply.Text = handler.ban(botctx.Context(update), update.EffectiveUser().ID, args[0], strings.Join(args[1:], " "))
+				}
+
+				if _, err := handler.api.Send(reply); err != nil {
+					log.Println(err)
+				}
+			},
+		),
+		telemux.NewCommandHandler(
+			"unban",
+			telemux.And(telemux.IsPrivate(), hasPermission(handler.permissionUsecase, model.PermissionBan)),
+			func(update *telemux.Update) {
+				args := update.Context["args"].([]string)
+				reply := tgbotapi.NewMessage(update.EffectiveChat().ID, UnbanUsageMessageText)
+
+				if len(args) == 1 {
+					reply.Text = handler.unban(botctx.Context(update), args[0])
+				}
+
+				if _, err := handler.api.Send(reply); err != nil {
+					log.Println(err)
+				}
+			},
+		),
+	)
+}
+
+func (handler *BanHandler) ban(ctx context.Context, bannedBy int64, arg string, reason string) string {
+	userID, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return BanUsageMessageText
+	}
+
+	_, err = handler.banUsecase.Ban(ctx, dto.CreateBan{
+		UserID:   userID,
+		Reason:   reason,
+		BannedBy: bannedBy,
+	})
+	if err != nil {
+		return errorText("ban user", err, map[apperror.Code]string{
+			apperror.Forbidden.Code: "Нельзя заблокировать администратора.",
+		})
+	}
+
+	return fmt.Sprintf("Пользователь %d заблокирован.", userID)
+}
+
+func (handler *BanHandler) unban(ctx context.Context, arg string) string {
+	userID, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return UnbanUsageMessageText
+	}
+
+	err = handler.banUsecase.Unban(ctx, userID)
+	if err != nil {
+		return errorText("unban user", err, map[apperror.Code]string{
+			apperror.NotFound.Code: "Пользователь не заблокирован.",
+		})
+	}
+
+	return fmt.Sprintf("Пользователь %d разблокирован.", userID)
+}
@@ -3,45 +3,94 @@ package handler
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/and3rson/telemux/v2"
 	"github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"golang.org/x/exp/slices"
-	"image"
-	_ "image/jpeg"
-	"image/png"
 	"log"
+	"markoslav/internal/bot/botctx"
 	"markoslav/internal/bot/template"
 	"markoslav/internal/dto"
 	"markoslav/internal/model"
+	"markoslav/internal/transfer"
 	"markoslav/internal/usecase"
 	"markoslav/pkg/apperror"
 	"markoslav/pkg/filter"
 	"math/rand"
-	"net/http"
+	"path/filepath"
+	"strings"
 	"time"
+	"unicode"
 )
 
 const (
 	RandomCaptionCommand = "марк"
+	CustomCaptionCommand = "caption"
+	CustomCaptionPrefix  = "марк:"
+	SimilarCaptionsCount = 3
+	MaxCaptionsFileSize  = 1 << 20
 	HelpMessageText      = `
 Вы можете управлять мной, посылая эти команды (только в приватном диалоге)
 
 /suggest - предложить новую подпись
+/caption <текст> - подписать фото своим текстом (в ответ на фото или в подписи к нему, также «марк: <текст>»)
 /approve - просмотр предложенных подписей (только для администрации)
+/modlog - журнал модерации (только для администрации)
+Файл .txt или .csv - добавить подписи списком, по одной на строку (только для администрации)
+/admin - управление администраторами (только для владельцев)
+/ban - заблокировать пользователя (только для администрации)
+/unban - разблокировать пользователя (только для администрации)
+/format - формат изображений в чате (для администраторов чата)
+/style - стиль подписей в чате (для администраторов чата)
+/custom - свои подписи в чате (для администраторов чата)
 /cancel - отменить текущую команду
 `
 	UnknownErrorMessageText = "Произошла непредвиденная ошибка."
+
+	CustomCaptionUsageMessageText     = "Ответьте командой /caption <текст> на сообщение с фото или отправьте её в подписи к фото."
+	CustomCaptionsDisabledMessageText = "Свои подписи отключены в этом чате."
 )
 
+// UploadFormats are the caption file formats moderators can upload, by file extension.
+var UploadFormats = map[string]transfer.Format{
+	".txt": transfer.FormatText,
+	".csv": transfer.FormatCSV,
+}
+
+var customCaptionErrorTexts = map[apperror.Code]string{
+	apperror.Forbidden.Code:       "Вам запрещено добавлять свои подписи.",
+	apperror.TooManyRequests.Code: "Вы подписываете фото слишком часто. Попробуйте позже.",
+}
+
+var captionErrorTexts = map[apperror.Code]string{
+	apperror.AlreadyExists.Code:   "Такая подпись уже существует. Попробуйте что-нибудь другое.",
+	apperror.Forbidden.Code:       "Вам запрещено предлагать подписи.",
+	apperror.TooManyRequests.Code: "Вы предлагаете подписи слишком часто. Попробуйте позже.",
+}
+
 type CaptionHandler struct {
-	api            *tgbotapi.BotAPI
-	captionUsecase usecase.CaptionUsecase
-	adminList      []int64
+	api                  botctx.API
+	captionUsecase       usecase.CaptionUsecase
+	permissionUsecase    usecase.PermissionUsecase
+	downloadUsecase      usecase.DownloadUsecase
+	renderedImageUsecase usecase.RenderedImageUsecase
+	imageUsecase         usecase.ImageUsecase
+	chatSettingsUsecase  usecase.ChatSettingsUsecase
 }
 
-func NewCaptionHandler(api *tgbotapi.BotAPI, captionUsecase usecase.CaptionUsecase, adminList []int64) *CaptionHandler {
-	return &CaptionHandler{api: api, captionUsecase: captionUsecase, adminList: adminList}
+func NewCaptionHandler(
+	api botctx.API,
+	captionUsecase usecase.CaptionUsecase,
+	permissionUsecase usecase.PermissionUsecase,
+	downloadUsecase usecase.DownloadUsecase,
+	renderedImageUsecase usecase.RenderedImageUsecase,
+	imageUsecase usecase.ImageUsecase,
+	chatSettingsUsecase usecase.ChatSettingsUsecase,
+) *CaptionHandler {
+	return &CaptionHandler{
+		api: api, captionUsecase: captionUsecase, permissionUsecase: permissionUsecase, downloadUsecase: downloadUsecase,
+		renderedImageUsecase: renderedImageUsecase, imageUsecase: imageUsecase, chatSettingsUsecase: chatSettingsUsecase,
+	}
 }
 
 func (handler *CaptionHandler) Register(mux *telemux.Mux) {
@@ -62,23 +111,21 @@ func (handler *CaptionHandler) Register(mux *telemux.Mux) {
 		),
 		telemux.NewConversationHandler(
 			"approve_captions",
-			telemux.NewLocalPersistence(),
+			botctx.NewLocalPersistence(),
 			telemux.StateMap{
 				"": {
 					telemux.NewCommandHandler(
 						"approve",
-						telemux.And(telemux.IsPrivate(), isAdmin(handler.adminList)),
+						telemux.And(telemux.IsPrivate(), hasPermission(handler.permissionUsecase, model.PermissionModerate)),
 						func(update *telemux.Update) {
 							options := filter.NewOptions().
 								Add("approved", false, filter.OperatorEq)
 
 							chat := update.EffectiveChat()
 
-							captions, err := handler.captionUsecase.Select(context.TODO(), 25, 0, options)
+							captions, err := handler.captionUsecase.Select(botctx.Context(update), 25, 0, options)
 							if err != nil {
-								handler.api.Send(tgbotapi.NewMessage(chat.ID, UnknownErrorMessageText))
-
-								log.Println(err)
+								handler.api.Send(tgbotapi.NewMessage(chat.ID, errorText("approve captions", err, nil)))
 								return
 							}
 
@@ -90,7 +137,7 @@ func (handler *CaptionHandler) Register(mux *telemux.Mux) {
 							update.PersistenceContext.PutDataValue("captions", captions)
 							update.PersistenceContext.PutDataValue("reviewed_caption_index", 0)
 
-							text, markup := ApprovingCaptionsMessageText(update)
+							text, markup := handler.ApprovingCaptionsMessageText(update)
 							message := tgbotapi.NewMessage(chat.ID, text)
 							message.ReplyMarkup = markup
 
@@ -113,7 +160,10 @@ func (handler *CaptionHandler) Register(mux *telemux.Mux) {
 							reviewedCaptionIndex := data["reviewed_caption_index"].(int)
 
 							caption := captions[reviewedCaptionIndex]
-							err := handler.captionUsecase.Approve(context.TODO(), caption.ID)
+							err := handler.captionUsecase.Approve(botctx.Context(update), dto.ModerateCaption{
+								ID:          caption.ID,
+								ModeratorID: update.EffectiveUser().ID,
+							})
 							if err != nil {
 								log.Println(err)
 								return
@@ -121,7 +171,7 @@ func (handler *CaptionHandler) Register(mux *telemux.Mux) {
 
 							update.PersistenceContext.PutDataValue("reviewed_caption_index", reviewedCaptionIndex+1)
 
-							text, markup := ApprovingCaptionsMessageText(update)
+							text, markup := handler.ApprovingCaptionsMessageText(update)
 
 							message := update.EffectiveMessage()
 							edit := tgbotapi.NewEditMessageText(message.Chat.ID, message.MessageID, text)
@@ -143,7 +193,10 @@ func (handler *CaptionHandler) Register(mux *telemux.Mux) {
 							reviewedCaptionIndex := data["reviewed_caption_index"].(int)
 
 							caption := captions[reviewedCaptionIndex]
-							err := handler.captionUsecase.Reject(context.TODO(), caption.ID)
+							err := handler.captionUsecase.Reject(botctx.Context(update), dto.ModerateCaption{
+								ID:          caption.ID,
+								ModeratorID: update.EffectiveUser().ID,
+							})
 							if err != nil {
 								log.Println(err)
 								return
@@ -151,7 +204,7 @@ func (handler *CaptionHandler) Register(mux *telemux.Mux) {
 
 							update.PersistenceContext.PutDataValue("reviewed_caption_index", reviewedCaptionIndex+1)
 
-							text, markup := ApprovingCaptionsMessageText(update)
+							text, markup := handler.ApprovingCaptionsMessageText(update)
 
 							message := update.EffectiveMessage()
 							edit := tgbotapi.NewEditMessageText(message.Chat.ID, message.MessageID, text)
@@ -164,6 +217,69 @@ func (handler *CaptionHandler) Register(mux *telemux.Mux) {
 							}
 						},
 					),
+					telemux.NewCallbackQueryHandler(
+						"edit_caption",
+						telemux.Any(),
+						func(update *telemux.Update) {
+							message := update.EffectiveMessage()
+							edit := tgbotapi.NewEditMessageText(
+								message.Chat.ID,
+								message.MessageID,
+								"Отправьте в чат новый текст подписи.",
+							)
+
+							if _, err := handler.api.Send(edit); err != nil {
+								log.Println(err)
+								return
+							}
+
+							update.PersistenceContext.SetState("editing_caption")
+						},
+					),
+				},
+				"editing_caption": {
+					telemux.NewMessageHandler(
+						telemux.And(telemux.HasText(), telemux.Not(telemux.IsAnyCommandMessage())),
+						func(update *telemux.Update) {
+							data := update.PersistenceContext.GetData()
+							captions := data["captions"].([]model.Caption)
+							reviewedCaptionIndex := data["reviewed_caption_index"].(int)
+
+							message := update.EffectiveMessage()
+
+							caption, err := handler.captionUsecase.Edit(botctx.Context(update), dto.EditCaption{
+								ID:          captions[reviewedCaptionIndex].ID,
+								Text:        message.Text,
+								ModeratorID: message.From.ID,
+							})
+							if err != nil {
+								detail := errorText("edit caption", err, captionErrorTexts)
+
+								reply := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Не удалось изменить подпись. %s", detail))
+								if _, err = handler.api.Send(reply); err != nil {
+									log.Println(err)
+								}
+
+								return
+							}
+
+							captions[reviewedCaptionIndex] = caption
+							update.PersistenceContext.PutDataValue("captions", captions)
+
+							text, markup := handler.ApprovingCaptionsMessageText(update)
+							reply := tgbotapi.NewMessage(message.Chat.ID, text)
+							if markup != nil {
+								reply.ReplyMarkup = markup
+							}
+
+							if _, err = handler.api.Send(reply); err != nil {
+								log.Println(err)
+								return
+							}
+
+							update.PersistenceContext.SetState("approving_captions")
+						},
+					),
 				},
 			},
 			[]*telemux.Handler{
@@ -184,6 +300,24 @@ func (handler *CaptionHandler) Register(mux *telemux.Mux) {
 							return
 						}
 
+						update.PersistenceContext.ClearData()
+						update.PersistenceContext.SetState("")
+					},
+				),
+				telemux.NewCommandHandler(
+					"cancel",
+					telemux.Any(),
+					func(update *telemux.Update) {
+						reply := tgbotapi.NewMessage(
+							update.EffectiveChat().ID,
+							"Команда /approve была успешно отменена.",
+						)
+
+						if _, err := handler.api.Send(reply); err != nil {
+							log.Println(err)
+							return
+						}
+
 						update.PersistenceContext.ClearData()
 						update.PersistenceContext.SetState("")
 					},
@@ -192,16 +326,17 @@ func (handler *CaptionHandler) Register(mux *telemux.Mux) {
 		),
 		telemux.NewConversationHandler(
 			"suggest_caption",
-			telemux.NewLocalPersistence(),
+			botctx.NewLocalPersistence(),
 			telemux.StateMap{
 				"": {
 					telemux.NewCommandHandler(
 						"suggest",
-						telemux.IsPrivate(),
+						telemux.And(telemux.IsPrivate(), hasPermission(handler.permissionUsecase, model.PermissionSuggest)),
 						func(update *telemux.Update) {
 							message := tgbotapi.NewMessage(
 								update.Message.Chat.ID,
-								"Отправьте в чат подпись, которую вы хотите предложить.",
+								"Отправьте в чат подпись, которую вы хотите предложить. "+
+									"Чтобы разместить текст сверху и снизу, разделите части знаком «|».",
 							)
 
 							if _, err := handler.api.Send(message); err != nil {
@@ -222,19 +357,16 @@ func (handler *CaptionHandler) Register(mux *telemux.Mux) {
 
 							reply := tgbotapi.NewMessage(message.Chat.ID, "Подпись была успешно отправлена на подтверждение.")
 
-							_, err := handler.captionUsecase.Create(context.TODO(), dto.CreateCaption{
+							_, err := handler.captionUsecase.Create(botctx.Context(update), dto.CreateCaption{
 								Text:     message.Text,
 								AuthorID: message.From.ID,
 							})
 							if err != nil {
-								detail := UnknownErrorMessageText
+								detail := errorText("enter caption", err, captionErrorTexts)
 
-								if _, ok := apperror.Is(err, apperror.Internal); ok {
-									log.Printf("enter caption: %s\n", err)
-								} else if _, ok = apperror.Is(err, apperror.AlreadyExists); ok {
-									detail = "Такая подпись уже существует. Попробуйте что-нибудь другое."
-									clearState = false
-								}
+								_, exists := apperror.Is(err, apperror.AlreadyExists)
+								_, invalid := apperror.Is(err, apperror.BadRequest)
+								clearState = !exists && !invalid
 
 								reply.Text = fmt.Sprintf("Не удалось отправить подпись. %s", detail)
 							}
@@ -275,6 +407,93 @@ func (handler *CaptionHandler) Register(mux *telemux.Mux) {
 				),
 			},
 		),
+		telemux.NewMessageHandler(
+			telemux.And(
+				telemux.IsPrivate(),
+				telemux.HasDocument(),
+				hasPermission(handler.permissionUsecase, model.PermissionModerate),
+			),
+			func(update *telemux.Update) {
+				message := update.EffectiveMessage()
+				document := message.Document
+
+				reply := tgbotapi.NewMessage(message.Chat.ID, "")
+				reply.ReplyToMessageID = message.MessageID
+
+				format, ok := UploadFormats[strings.ToLower(filepath.Ext(document.FileName))]
+				if !ok {
+					reply.Text = "Поддерживаются только файлы .txt и .csv."
+				} else {
+					result, err := handler.uploadCaptions(botctx.Context(update), document.FileID, document.FileSize, format, message.From.ID)
+					if err != nil {
+						reply.Text = errorText("upload captions", err, nil)
+					} else {
+						reply.Text = fmt.Sprintf(
+							"Добавлено подписей: %d\nДубликатов: %d\nНекорректных строк: %d",
+							result.Inserted, result.Duplicates, result.Invalid,
+						)
+					}
+				}
+
+				if _, err := handler.api.Send(reply); err != nil {
+					log.Println(err)
+				}
+			},
+		),
+		telemux.NewMessageHandler(
+			telemux.And(
+				func(update *telemux.Update) bool {
+					message := update.Message
+					if message == nil {
+						return false
+					}
+
+					text, command, ok := customCaptionText(message, update.Bot.Self.UserName)
+					if !ok {
+						return false
+					}
+
+					photos := message.Photo
+					if len(photos) == 0 && message.ReplyToMessage != nil {
+						photos = message.ReplyToMessage.Photo
+					}
+
+					// Without a photo "марк: ..." is an ordinary message, only the command asks for usage.
+					if len(photos) == 0 && !command {
+						return false
+					}
+
+					update.Context["text"] = text
+					if len(photos) > 0 {
+						update.Context["photo"] = photos[len(photos)-1]
+					}
+
+					return true
+				},
+				hasPermission(handler.permissionUsecase, model.PermissionSuggest),
+			),
+			func(update *telemux.Update) {
+				message := update.Message
+				text := update.Context["text"].(string)
+				photo, hasPhoto := update.Context["photo"].(tgbotapi.PhotoSize)
+
+				reply := tgbotapi.NewMessage(message.Chat.ID, CustomCaptionUsageMessageText)
+				reply.ReplyToMessageID = message.MessageID
+
+				if hasPhoto && text != "" {
+					err := handler.sendCustomCaption(botctx.Context(update), message, photo, text)
+					if err == nil {
+						return
+					}
+
+					reply.Text = fmt.Sprintf("Не удалось подписать фото. %s", errorText("send custom caption", err, customCaptionErrorTexts))
+				}
+
+				if _, err := handler.api.Send(reply); err != nil {
+					log.Println(err)
+				}
+			},
+		),
 		telemux.NewMessageHandler(
 			func(update *telemux.Update) bool {
 				message := update.Message
@@ -292,6 +511,7 @@ func (handler *CaptionHandler) Register(mux *telemux.Mux) {
 
 				if text == RandomCaptionCommand || ((rand.Intn(100) < 50) && isReply == false) && len(message.Photo) > 0 {
 					update.Context["photo"] = message.Photo[len(message.Photo)-1]
+					update.Context["requested"] = text == RandomCaptionCommand
 
 					return true
 				}
@@ -301,73 +521,269 @@ func (handler *CaptionHandler) Register(mux *telemux.Mux) {
 			func(update *telemux.Update) {
 				photo := update.Context["photo"].(tgbotapi.PhotoSize)
 
-				fileURL, err := handler.api.GetFileDirectURL(photo.FileID)
-				if err != nil {
-					log.Printf("get file direct url: %s", err)
-					return
-				}
-
-				var img image.Image
-				img, err = fetchImage(fileURL)
-				if err != nil {
-					log.Printf("fetch image: %s", err)
-					return
-				}
-
-				img, err = handler.captionUsecase.DrawRandom(context.TODO(), img)
-				if err != nil {
-					log.Printf("draw random caption: %s", err)
-					return
-				}
+				if err := handler.sendRandomCaption(botctx.Context(update), update.Message, photo); err != nil {
+					text := errorText("send random caption", err, nil)
 
-				buffer := new(bytes.Buffer)
-				if err = png.Encode(buffer, img); err != nil {
-					log.Printf("encode image: %s", err)
-					return
-				}
+					// Only answer when the caption was asked for, random captions fail silently.
+					if !update.Context["requested"].(bool) {
+						return
+					}
 
-				photoConfig := tgbotapi.NewPhoto(update.Message.Chat.ID, tgbotapi.FileBytes{
-					Name:  "picture",
-					Bytes: buffer.Bytes(),
-				})
-				photoConfig.ReplyToMessageID = update.Message.MessageID
+					reply := tgbotapi.NewMessage(update.Message.Chat.ID, text)
+					reply.ReplyToMessageID = update.Message.MessageID
 
-				if _, err = handler.api.Send(photoConfig); err != nil {
-					log.Printf("failed to send: %s", err)
-					return
+					if _, err = handler.api.Send(reply); err != nil {
+						log.Println(err)
+					}
 				}
 			},
 		),
 	)
 }
 
-func fetchImage(url string) (image.Image, error) {
-	response, err := http.Get(url)
+// sendRandomCaption replies to the message with the photo and a random caption on it.
+// A photo already captioned the same way is resent by its Telegram file ID.
+func (handler *CaptionHandler) sendRandomCaption(ctx context.Context, message *tgbotapi.Message, photo tgbotapi.PhotoSize) error {
+	caption, err := handler.captionUsecase.GetRandom(ctx)
+	if err != nil {
+		return err
+	}
+
+	settings, err := handler.chatSettingsUsecase.Get(ctx, message.Chat.ID)
+	if err != nil {
+		return err
+	}
+
+	key := dto.RenderedImageKey{
+		SourceFileUniqueID: photo.FileUniqueID,
+		CaptionID:          caption.ID,
+		Style:              imageStyle(settings),
+	}
+
+	fileID, err := handler.renderedImageUsecase.GetFileID(ctx, key)
+	if err == nil {
+		photoConfig := tgbotapi.NewPhoto(message.Chat.ID, tgbotapi.FileID(fileID))
+		photoConfig.ReplyToMessageID = message.MessageID
+
+		handler.api.Post(photoConfig, func(_ tgbotapi.Message, err error) {
+			if err == nil || errors.Is(err, botctx.ErrSenderStopped) {
+				return
+			}
+
+			// The file may be gone from Telegram, so the photo is rendered again.
+			log.Printf("send rendered image %s: %s", fileID, err)
+
+			handler.api.Go(func() {
+				if err := handler.renderRandomCaption(ctx, message, photo, caption, settings, key); err != nil {
+					log.Printf("send random caption: %s", err)
+				}
+			})
+		})
+
+		return nil
+	}
+
+	if _, ok := apperror.Is(err, apperror.NotFound); !ok {
+		log.Printf("get rendered image: %s", err)
+	}
+
+	return handler.renderRandomCaption(ctx, message, photo, caption, settings, key)
+}
+
+// renderRandomCaption draws the caption on the photo and saves the file ID of the sent photo under key.
+func (handler *CaptionHandler) renderRandomCaption(
+	ctx context.Context,
+	message *tgbotapi.Message,
+	photo tgbotapi.PhotoSize,
+	caption model.Caption,
+	settings model.ChatSettings,
+	key dto.RenderedImageKey,
+) error {
+	return handler.sendCaption(ctx, message, photo, caption, settings, func(sent tgbotapi.Message) {
+		if len(sent.Photo) == 0 {
+			return
+		}
+
+		err := handler.renderedImageUsecase.Save(ctx, dto.SaveRenderedImage{
+			RenderedImageKey: key,
+			FileID:           sent.Photo[len(sent.Photo)-1].FileID,
+		})
+		if err != nil {
+			log.Printf("save rendered image: %s", err)
+		}
+	})
+}
+
+// sendCustomCaption replies to the message with the photo and the user's text on it.
+// Custom captions are not saved, so their renders are not cached either.
+func (handler *CaptionHandler) sendCustomCaption(
+	ctx context.Context, message *tgbotapi.Message, photo tgbotapi.PhotoSize, text string,
+) error {
+	settings, err := handler.chatSettingsUsecase.Get(ctx, message.Chat.ID)
+	if err != nil {
+		return err
+	}
+
+	if settings.CustomCaptionsDisabled {
+		return apperror.BadRequest.WithMessage(CustomCaptionsDisabledMessageText)
+	}
+
+	caption, err := handler.captionUsecase.Custom(ctx, dto.CustomCaption{
+		Text:     text,
+		AuthorID: message.From.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return handler.sendCaption(ctx, message, photo, caption, settings, nil)
+}
+
+// sendCaption downloads the photo, draws the caption on it and queues it as a reply to the message.
+// The photo is sent in the background, so that handlers do not wait for the group rate limit;
+// onSent, if set, is called in the background once it is sent.
+func (handler *CaptionHandler) sendCaption(
+	ctx context.Context,
+	message *tgbotapi.Message,
+	photo tgbotapi.PhotoSize,
+	caption model.Caption,
+	settings model.ChatSettings,
+	onSent func(sent tgbotapi.Message),
+) error {
+	fileURL, err := handler.api.GetFileDirectURL(photo.FileID)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	img, err := handler.downloadUsecase.DownloadImage(ctx, dto.Download{
+		URL:  fileURL,
+		Key:  photo.FileUniqueID,
+		Size: photo.FileSize,
+	})
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status code: %d", response.StatusCode)
+	img, err = handler.captionUsecase.Draw(ctx, caption, img, dto.DrawOptions{TextStyle: settings.TextStyle})
+	if err != nil {
+		return err
 	}
 
-	var img image.Image
-	img, _, err = image.Decode(response.Body)
+	encoded, err := handler.imageUsecase.Encode(ctx, img, settings.ImageFormat)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return img, nil
+	photoConfig := tgbotapi.NewPhoto(message.Chat.ID, tgbotapi.FileBytes{
+		Name:  "picture" + encoded.Format.Extension(),
+		Bytes: encoded.Data,
+	})
+	photoConfig.ReplyToMessageID = message.MessageID
+
+	handler.api.Post(photoConfig, func(sent tgbotapi.Message, err error) {
+		if err != nil {
+			log.Printf("send captioned photo: %s", err)
+			return
+		}
+
+		if onSent != nil {
+			handler.api.Go(func() { onSent(sent) })
+		}
+	})
+
+	return nil
 }
 
-func isAdmin(adminList []int64) telemux.FilterFunc {
-	return func(update *telemux.Update) bool {
-		return slices.Contains(adminList, update.EffectiveUser().ID)
+// customCaptionText extracts the text of "/caption <text>" or "марк: <text>" from a message or a photo caption.
+// Command is set for the /caption command, which is also recognized without text. In groups the command
+// may be addressed to a bot, "/caption@name", and is ignored unless the name is botName.
+func customCaptionText(message *tgbotapi.Message, botName string) (text string, command bool, ok bool) {
+	raw := message.Text
+	if raw == "" {
+		raw = message.Caption
 	}
+
+	if strings.HasPrefix(raw, "/") {
+		end := strings.IndexFunc(raw, unicode.IsSpace)
+		if end < 0 {
+			end = len(raw)
+		}
+
+		name, mention, addressed := strings.Cut(raw[1:end], "@")
+		if name != CustomCaptionCommand || addressed && !strings.EqualFold(mention, botName) {
+			return "", false, false
+		}
+
+		return strings.TrimSpace(raw[end:]), true, true
+	}
+
+	if len(raw) >= len(CustomCaptionPrefix) && strings.EqualFold(raw[:len(CustomCaptionPrefix)], CustomCaptionPrefix) {
+		return strings.TrimSpace(raw[len(CustomCaptionPrefix):]), false, true
+	}
+
+	return "", false, false
+}
+
+// imageStyle identifies how images are rendered for the chat, so cached renders of other styles are not reused.
+func imageStyle(settings model.ChatSettings) string {
+	if settings.ImageFormat == model.ImageFormatDefault && settings.TextStyle == model.TextStyleDefault {
+		return model.DefaultImageStyle
+	}
+
+	format, textStyle := string(settings.ImageFormat), string(settings.TextStyle)
+	if format == "" {
+		format = model.DefaultImageStyle
+	}
+	if textStyle == "" {
+		textStyle = model.DefaultImageStyle
+	}
+
+	return format + "/" + textStyle
 }
 
-func ApprovingCaptionsMessageText(update *telemux.Update) (string, *tgbotapi.InlineKeyboardMarkup) {
+func (handler *CaptionHandler) uploadCaptions(ctx context.Context, fileID string, size int, format transfer.Format, authorID int64) (dto.ImportCaptionsResult, error) {
+	fileURL, err := handler.api.GetFileDirectURL(fileID)
+	if err != nil {
+		return dto.ImportCaptionsResult{}, err
+	}
+
+	data, err := handler.downloadUsecase.Download(ctx, dto.Download{
+		URL:      fileURL,
+		Size:     size,
+		MaxBytes: MaxCaptionsFileSize,
+	})
+	if err != nil {
+		return dto.ImportCaptionsResult{}, err
+	}
+
+	records, err := transfer.ReadCaptions(bytes.NewReader(data), format)
+	if err != nil {
+		return dto.ImportCaptionsResult{}, err
+	}
+
+	invalid := 0
+
+	requests := make([]dto.CreateCaption, 0, len(records))
+	for _, record := range records {
+		if record.Invalid {
+			invalid++
+			continue
+		}
+
+		requests = append(requests, dto.CreateCaption{
+			Text:     record.Text,
+			AuthorID: authorID,
+			Approved: true,
+		})
+	}
+
+	result, err := handler.captionUsecase.Import(ctx, requests)
+	result.Invalid += invalid
+
+	return result, err
+}
+
+func (handler *CaptionHandler) ApprovingCaptionsMessageText(update *telemux.Update) (string, *tgbotapi.InlineKeyboardMarkup) {
 	data := update.PersistenceContext.GetData()
 	captions := data["captions"].([]model.Caption)
 	reviewedCaptionIndex := data["reviewed_caption_index"].(int)
@@ -381,13 +797,26 @@ func ApprovingCaptionsMessageText(update *telemux.Update) (string, *tgbotapi.Inl
 
 	caption := captions[reviewedCaptionIndex]
 
+	similar, err := handler.captionUsecase.SelectSimilar(botctx.Context(update), caption, SimilarCaptionsCount)
+	if err != nil {
+		log.Printf("select similar captions: %s", err)
+	}
+
+	similarTexts := make([]string, 0, len(similar))
+	for _, similarCaption := range similar {
+		similarTexts = append(similarTexts, similarCaption.Text)
+	}
+
 	buffer := new(bytes.Buffer)
-	err := template.ApproveCaptions.Execute(buffer, map[string]any{
+	err = template.ApproveCaptions.Execute(buffer, map[string]any{
 		"reviewed_count":             len(captions) - reviewedCaptionIndex,
 		"total_disapproved_remained": len(captions),
 		"text":                       caption.Text,
+		"top_text":                   caption.TopText,
+		"bottom_text":                caption.BottomText,
 		"author_id":                  caption.AuthorID,
 		"created_at":                 caption.CreatedAt.Format(time.RFC3339),
+		"similar":                    similarTexts,
 	})
 	if err != nil {
 		update.PersistenceContext.ClearData()
@@ -400,6 +829,7 @@ func ApprovingCaptionsMessageText(update *telemux.Update) (string, *tgbotapi.Inl
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("✔️", "approve_caption"),
 			tgbotapi.NewInlineKeyboardButtonData("❌", "reject_caption"),
+			tgbotapi.NewInlineKeyboardButtonData("✏️", "edit_caption"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("Отмена", "cancel"),
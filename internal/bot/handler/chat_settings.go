@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/and3rson/telemux/v2"
+	"github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"markoslav/internal/bot/botctx"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/usecase"
+)
+
+const (
+	FormatUsageMessageText = "Использование: /format [jpeg|png|default]"
+	StyleUsageMessageText  = "Использование: /style [classic|auto|backdrop|default]"
+	CustomUsageMessageText = "Использование: /custom [on|off]"
+)
+
+var ImageFormatNames = map[model.ImageFormat]string{
+	model.ImageFormatDefault: "по умолчанию",
+	model.ImageFormatJPEG:    "JPEG",
+	model.ImageFormatPNG:     "PNG",
+}
+
+var TextStyleNames = map[model.TextStyle]string{
+	model.TextStyleDefault:  "по умолчанию",
+	model.TextStyleClassic:  "белый текст с тенью",
+	model.TextStyleAuto:     "цвет текста по фону",
+	model.TextStyleBackdrop: "текст на подложке",
+}
+
+var CustomCaptionsNames = map[bool]string{
+	false: "включены",
+	true:  "отключены",
+}
+
+type ChatSettingsHandler struct {
+	api                 botctx.API
+	chatSettingsUsecase usecase.ChatSettingsUsecase
+	permissionUsecase   usecase.PermissionUsecase
+}
+
+func NewChatSettingsHandler(
+	api botctx.API,
+	chatSettingsUsecase usecase.ChatSettingsUsecase,
+	permissionUsecase usecase.PermissionUsecase,
+) *ChatSettingsHandler {
+	return &ChatSettingsHandler{api: api, chatSettingsUsecase: chatSettingsUsecase, permissionUsecase: permissionUsecase}
+}
+
+func (handler *ChatSettingsHandler) Register(mux *telemux.Mux) {
+	mux.AddHandler(
+		telemux.NewCommandHandler(
+			"format",
+			hasPermission(handler.permissionUsecase, model.PermissionManageChat),
+			func(update *telemux.Update) {
+				args := update.Context["args"].([]string)
+				chat := update.EffectiveChat()
+
+				var text string
+				switch len(args) {
+				case 0:
+					text = handler.showFormat(botctx.Context(update), chat.ID)
+				case 1:
+					text = handler.setFormat(botctx.Context(update), chat.ID, update.EffectiveUser().ID, args[0])
+				default:
+					text = FormatUsageMessageText
+				}
+
+				handler.reply(chat.ID, text)
+			},
+		),
+		telemux.NewCommandHandler(
+			"style",
+			hasPermission(handler.permissionUsecase, model.PermissionManageChat),
+			func(update *telemux.Update) {
+				args := update.Context["args"].([]string)
+				chat := update.EffectiveChat()
+
+				var text string
+				switch len(args) {
+				case 0:
+					text = handler.showStyle(botctx.Context(update), chat.ID)
+				case 1:
+					text = handler.setStyle(botctx.Context(update), chat.ID, update.EffectiveUser().ID, args[0])
+				default:
+					text = StyleUsageMessageText
+				}
+
+				handler.reply(chat.ID, text)
+			},
+		),
+		telemux.NewCommandHandler(
+			"custom",
+			hasPermission(handler.permissionUsecase, model.PermissionManageChat),
+			func(update *telemux.Update) {
+				args := update.Context["args"].([]string)
+				chat := update.EffectiveChat()
+
+				var text string
+				switch {
+				case len(args) == 0:
+					text = handler.showCustom(botctx.Context(update), chat.ID)
+				case len(args) == 1 && (args[0] == "on" || args[0] == "off"):
+					text = handler.setCustom(botctx.Context(update), chat.ID, update.EffectiveUser().ID, args[0] == "off")
+				default:
+					text = CustomUsageMessageText
+				}
+
+				handler.reply(chat.ID, text)
+			},
+		),
+	)
+}
+
+// reply answers chat admins ahead of the group's captioned photos.
+func (handler *ChatSettingsHandler) reply(chatID int64, text string) {
+	message := botctx.WithPriority(tgbotapi.NewMessage(chatID, text), botctx.PriorityHigh)
+	if _, err := handler.api.Send(message); err != nil {
+		log.Println(err)
+	}
+}
+
+func (handler *ChatSettingsHandler) showFormat(ctx context.Context, chatID int64) string {
+	settings, err := handler.chatSettingsUsecase.Get(ctx, chatID)
+	if err != nil {
+		return errorText("get chat settings", err, nil)
+	}
+
+	return fmt.Sprintf("Формат изображений: %s\n\n%s", ImageFormatNames[settings.ImageFormat], FormatUsageMessageText)
+}
+
+func (handler *ChatSettingsHandler) setFormat(ctx context.Context, chatID int64, updatedBy int64, arg string) string {
+	format := model.ImageFormat(arg)
+	if arg == "default" {
+		format = model.ImageFormatDefault
+	}
+
+	settings, err := handler.chatSettingsUsecase.Update(ctx, dto.UpdateChatSettings{
+		ChatID:      chatID,
+		ImageFormat: &format,
+		UpdatedBy:   updatedBy,
+	})
+	if err != nil {
+		return errorText("update chat settings", err, nil)
+	}
+
+	return fmt.Sprintf("Формат изображений: %s", ImageFormatNames[settings.ImageFormat])
+}
+
+func (handler *ChatSettingsHandler) showStyle(ctx context.Context, chatID int64) string {
+	settings, err := handler.chatSettingsUsecase.Get(ctx, chatID)
+	if err != nil {
+		return errorText("get chat settings", err, nil)
+	}
+
+	return fmt.Sprintf("Стиль подписей: %s\n\n%s", TextStyleNames[settings.TextStyle], StyleUsageMessageText)
+}
+
+func (handler *ChatSettingsHandler) setStyle(ctx context.Context, chatID int64, updatedBy int64, arg string) string {
+	style := model.TextStyle(arg)
+	if arg == "default" {
+		style = model.TextStyleDefault
+	}
+
+	settings, err := handler.chatSettingsUsecase.Update(ctx, dto.UpdateChatSettings{
+		ChatID:    chatID,
+		TextStyle: &style,
+		UpdatedBy: updatedBy,
+	})
+	if err != nil {
+		return errorText("update chat settings", err, nil)
+	}
+
+	return fmt.Sprintf("Стиль подписей: %s", TextStyleNames[settings.TextStyle])
+}
+
+func (handler *ChatSettingsHandler) showCustom(ctx context.Context, chatID int64) string {
+	settings, err := handler.chatSettingsUsecase.Get(ctx, chatID)
+	if err != nil {
+		return errorText("get chat settings", err, nil)
+	}
+
+	return fmt.Sprintf("Свои подписи: %s\n\n%s", CustomCaptionsNames[settings.CustomCaptionsDisabled], CustomUsageMessageText)
+}
+
+func (handler *ChatSettingsHandler) setCustom(ctx context.Context, chatID int64, updatedBy int64, disabled bool) string {
+	settings, err := handler.chatSettingsUsecase.Update(ctx, dto.UpdateChatSettings{
+		ChatID:                 chatID,
+		CustomCaptionsDisabled: &disabled,
+		UpdatedBy:              updatedBy,
+	})
+	if err != nil {
+		return errorText("update chat settings", err, nil)
+	}
+
+	return fmt.Sprintf("Свои подписи: %s", CustomCaptionsNames[settings.CustomCaptionsDisabled])
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"log"
+	"markoslav/pkg/apperror"
+	"net/http"
+)
+
+// errorText returns a user-facing description of err and logs it if it is an internal one.
+// Overrides replace registered messages with ones that fit the context of a particular command.
+func errorText(scope string, err error, overrides map[apperror.Code]string) string {
+	if apperror.HTTPStatus(err) >= http.StatusInternalServerError {
+		log.Printf("%s: %s", scope, err)
+	}
+
+	if text, ok := overrides[apperror.As(err).Code]; ok {
+		return text
+	}
+
+	return apperror.UserMessage(err, apperror.DefaultLanguage)
+}
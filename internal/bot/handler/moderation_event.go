@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/and3rson/telemux/v2"
+	"github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"markoslav/internal/bot/botctx"
+	"markoslav/internal/bot/template"
+	"markoslav/internal/model"
+	"markoslav/internal/usecase"
+	"markoslav/pkg/filter"
+	"strconv"
+	"time"
+	"unicode/utf16"
+)
+
+const ModerationLogPageSize = 10
+
+// Caption texts and reasons are shortened in the log, so a full page fits into a Telegram message
+// of 4096 UTF-16 code units: an edit reason alone holds two caption texts.
+const (
+	ModerationLogCaptionLength = 100
+	ModerationLogReasonLength  = 180
+)
+
+var ModerationActionNames = map[model.ModerationAction]string{
+	model.ModerationActionApprove: "одобрена",
+	model.ModerationActionReject:  "отклонена",
+	model.ModerationActionEdit:    "изменена",
+	model.ModerationActionDelete:  "удалена",
+}
+
+type ModerationEventHandler struct {
+	api                    botctx.API
+	moderationEventUsecase usecase.ModerationEventUsecase
+	permissionUsecase      usecase.PermissionUsecase
+}
+
+func NewModerationEventHandler(
+	api botctx.API,
+	moderationEventUsecase usecase.ModerationEventUsecase,
+	permissionUsecase usecase.PermissionUsecase,
+) *ModerationEventHandler {
+	return &ModerationEventHandler{
+		api:                    api,
+		moderationEventUsecase: moderationEventUsecase,
+		permissionUsecase:      permissionUsecase,
+	}
+}
+
+func (handler *ModerationEventHandler) Register(mux *telemux.Mux) {
+	mux.AddHandler(
+		telemux.NewCommandHandler(
+			"modlog",
+			telemux.And(telemux.IsPrivate(), hasPermission(handler.permissionUsecase, model.PermissionModerate)),
+			func(update *telemux.Update) {
+				chat := update.EffectiveChat()
+
+				text, markup, err := handler.moderationLogMessageText(botctx.Context(update), 0)
+				if err != nil {
+					handler.api.Send(tgbotapi.NewMessage(chat.ID, errorText("moderation log", err, nil)))
+					return
+				}
+
+				message := tgbotapi.NewMessage(chat.ID, text)
+				if markup != nil {
+					message.ReplyMarkup = markup
+				}
+
+				if _, err = handler.api.Send(message); err != nil {
+					log.Println(err)
+				}
+			},
+		),
+		telemux.NewCallbackQueryHandler(
+			`^modlog:(\d+)$`,
+			hasPermission(handler.permissionUsecase, model.PermissionModerate),
+			func(update *telemux.Update) {
+				page, err := strconv.Atoi(update.Context["matches"].([]string)[1])
+				if err != nil {
+					return
+				}
+
+				message := update.EffectiveMessage()
+
+				text, markup, err := handler.moderationLogMessageText(botctx.Context(update), page)
+				if err != nil {
+					text = errorText("moderation log", err, nil)
+				}
+
+				edit := tgbotapi.NewEditMessageText(message.Chat.ID, message.MessageID, text)
+				if markup != nil {
+					edit.ReplyMarkup = markup
+				}
+
+				if _, err = handler.api.Send(edit); err != nil {
+					log.Println(err)
+				}
+			},
+		),
+	)
+}
+
+func (handler *ModerationEventHandler) moderationLogMessageText(ctx context.Context, page int) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	events, err := handler.moderationEventUsecase.Select(
+		ctx,
+		ModerationLogPageSize+1,
+		page*ModerationLogPageSize,
+		filter.NewOptions(),
+	)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(events) == 0 {
+		return "Журнал модерации пуст.", nil, nil
+	}
+
+	hasNext := len(events) > ModerationLogPageSize
+	if hasNext {
+		events = events[:ModerationLogPageSize]
+	}
+
+	items := make([]map[string]any, 0, len(events))
+	for _, event := range events {
+		items = append(items, map[string]any{
+			"created_at":   event.CreatedAt.Format(time.RFC3339),
+			"action":       ModerationActionNames[event.Action],
+			"moderator_id": event.ModeratorID,
+			"caption_text": truncate(event.CaptionText, ModerationLogCaptionLength),
+			"reason":       truncate(event.Reason, ModerationLogReasonLength),
+		})
+	}
+
+	buffer := new(bytes.Buffer)
+	err = template.ModerationLog.Execute(buffer, map[string]any{
+		"page":   page + 1,
+		"events": items,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var buttons []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData("◀️", fmt.Sprintf("modlog:%d", page-1)))
+	}
+	if hasNext {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData("▶️", fmt.Sprintf("modlog:%d", page+1)))
+	}
+
+	if len(buttons) == 0 {
+		return buffer.String(), nil, nil
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(buttons...))
+
+	return buffer.String(), &keyboard, nil
+}
+
+// truncate shortens text to at most length UTF-16 code units, which Telegram counts message lengths in.
+func truncate(text string, length int) string {
+	if len(utf16.Encode([]rune(text))) <= length {
+		return text
+	}
+
+	units := 0
+	for i, r := range text {
+		// Characters outside the BMP take two units, one unit is left for the ellipsis.
+		units++
+		if r >= 0x10000 {
+			units++
+		}
+		if units > length-1 {
+			return text[:i] + "…"
+		}
+	}
+
+	return text
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"github.com/and3rson/telemux/v2"
+	"log"
+	"markoslav/internal/bot/botctx"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/usecase"
+)
+
+func hasPermission(permissionUsecase usecase.PermissionUsecase, permission model.Permission) telemux.FilterFunc {
+	return func(update *telemux.Update) bool {
+		user, chat := update.EffectiveUser(), update.EffectiveChat()
+		if user == nil || chat == nil {
+			return false
+		}
+
+		allowed, err := permissionUsecase.Check(botctx.Context(update), dto.CheckPermission{
+			UserID:     user.ID,
+			ChatID:     chat.ID,
+			Permission: permission,
+		})
+		if err != nil {
+			log.Printf("check permission %s: %s", permission, err)
+			return false
+		}
+
+		return allowed
+	}
+}
@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"markoslav/internal/metrics"
+	"sync"
+	"time"
+)
+
+// pool processes updates concurrently. Updates are sharded by chat ID, so updates
+// from one chat are always handled by the same worker in the order they arrived.
+type pool struct {
+	queues   []chan tgbotapi.Update
+	timeout  time.Duration
+	dispatch func(update tgbotapi.Update)
+	wg       sync.WaitGroup
+}
+
+func newPool(size int, queueSize int, timeout time.Duration, dispatch func(update tgbotapi.Update)) *pool {
+	if size < 1 {
+		size = 1
+	}
+
+	queues := make([]chan tgbotapi.Update, size)
+	for i := range queues {
+		queues[i] = make(chan tgbotapi.Update, queueSize)
+	}
+
+	return &pool{
+		queues:   queues,
+		timeout:  timeout,
+		dispatch: dispatch,
+	}
+}
+
+func (pool *pool) Start() {
+	for _, queue := range pool.queues {
+		pool.wg.Add(1)
+
+		go pool.work(queue)
+	}
+}
+
+// Submit queues the update. When the worker's queue is full it waits up to the timeout
+// and drops the update if there is still no room, returning false.
+func (pool *pool) Submit(update tgbotapi.Update) bool {
+	queue := pool.queues[shardKey(update)%uint64(len(pool.queues))]
+
+	select {
+	case queue <- update:
+		metrics.UpdatesQueued.Inc()
+		return true
+	default:
+	}
+
+	start := time.Now()
+	timer := time.NewTimer(pool.timeout)
+	defer timer.Stop()
+
+	select {
+	case queue <- update:
+		metrics.UpdatesQueued.Inc()
+		metrics.UpdateQueueWait.Observe(time.Since(start).Seconds())
+		return true
+	case <-timer.C:
+		metrics.UpdatesDropped.Inc()
+		log.Printf("update %d dropped: queue is full", update.UpdateID)
+		return false
+	}
+}
+
+// Stop closes the queues and waits until the queued updates are processed.
+func (pool *pool) Stop() {
+	for _, queue := range pool.queues {
+		close(queue)
+	}
+
+	pool.wg.Wait()
+}
+
+func (pool *pool) work(queue chan tgbotapi.Update) {
+	defer pool.wg.Done()
+
+	for update := range queue {
+		metrics.UpdatesQueued.Dec()
+		pool.dispatch(update)
+	}
+}
+
+func shardKey(update tgbotapi.Update) uint64 {
+	if chat := update.FromChat(); chat != nil {
+		return uint64(chat.ID)
+	}
+
+	if user := update.SentFrom(); user != nil {
+		return uint64(user.ID)
+	}
+
+	return 0
+}
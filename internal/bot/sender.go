@@ -0,0 +1,373 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"markoslav/internal/bot/botctx"
+	"markoslav/internal/config"
+	"markoslav/internal/metrics"
+	"markoslav/pkg/ratelimit"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// senderWorkers is the number of requests sent to Telegram at the same time.
+const senderWorkers = 4
+
+// Sender sends messages within Telegram flood limits: a global rate, a per-chat rate for
+// private chats and a lower one for groups. Requests wait for their chat's turn in the queue,
+// where workers take the most urgent ready one: private chats and admin replies first, photos
+// to groups last. Requests to the same chat are sent one at a time in the order they came, so a
+// reply never overtakes the photo it follows. Requests are retried after the delay Telegram asks for on 429.
+type Sender struct {
+	api     *tgbotapi.BotAPI
+	global  *ratelimit.Bucket
+	chat    *ratelimit.Bucket
+	group   *ratelimit.Bucket
+	retries int
+
+	mu      sync.Mutex
+	queue   []*sendJob
+	seq     uint64
+	stopped bool
+	// sending holds the chats with a request in flight.
+	sending map[int64]bool
+
+	wake chan struct{}
+	stop chan struct{}
+	done sync.WaitGroup
+	// background tracks work started with Go.
+	background sync.WaitGroup
+}
+
+func NewSender(api *tgbotapi.BotAPI, conf config.Send) *Sender {
+	sender := &Sender{
+		api:     api,
+		global:  ratelimit.NewBucket(conf.GlobalRate),
+		chat:    ratelimit.NewBucket(conf.ChatRate),
+		group:   ratelimit.NewBucket(conf.GroupRate),
+		retries: conf.Retries,
+		sending: make(map[int64]bool),
+		wake:    make(chan struct{}, senderWorkers),
+		stop:    make(chan struct{}),
+	}
+
+	sender.done.Add(senderWorkers)
+	for i := 0; i < senderWorkers; i++ {
+		go sender.work()
+	}
+
+	return sender
+}
+
+// Send queues the request and waits for its result.
+func (sender *Sender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	result := make(chan sendResult, 1)
+
+	sender.enqueue(c, func(message tgbotapi.Message, err error) {
+		result <- sendResult{message: message, err: err}
+	})
+
+	r := <-result
+
+	return r.message, r.err
+}
+
+// Post queues the request and returns at once. Done is called with the result from a sender
+// worker, so it must not block; errors are only logged when done is nil.
+func (sender *Sender) Post(c tgbotapi.Chattable, done func(tgbotapi.Message, error)) {
+	if done == nil {
+		done = func(_ tgbotapi.Message, err error) {
+			if err != nil {
+				log.Printf("send: %s", err)
+			}
+		}
+	}
+
+	sender.enqueue(c, done)
+}
+
+func (sender *Sender) GetFileDirectURL(fileID string) (string, error) {
+	return sender.api.GetFileDirectURL(fileID)
+}
+
+func (sender *Sender) Go(f func()) {
+	sender.background.Add(1)
+	go func() {
+		defer sender.background.Done()
+		f()
+	}()
+}
+
+// Stop stops accepting messages and waits until the queued ones are sent and the work started
+// with Go is done. When ctx expires first, the queued messages fail with botctx.ErrSenderStopped.
+func (sender *Sender) Stop(ctx context.Context) error {
+	sender.mu.Lock()
+	sender.stopped = true
+	sender.mu.Unlock()
+
+	close(sender.stop)
+
+	// Callbacks of the sent messages may start background work, so it is waited for after the workers.
+	if wait(ctx, &sender.done) {
+		if wait(ctx, &sender.background) {
+			return nil
+		}
+
+		return ctx.Err()
+	}
+
+	sender.mu.Lock()
+	queue := sender.queue
+	sender.queue = nil
+	sender.mu.Unlock()
+
+	for _, job := range queue {
+		metrics.TelegramSendQueued.Dec()
+		job.done(tgbotapi.Message{}, botctx.ErrSenderStopped)
+	}
+
+	return ctx.Err()
+}
+
+// wait waits for wg and reports whether it was done before ctx expired.
+func wait(ctx context.Context, wg *sync.WaitGroup) bool {
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (sender *Sender) enqueue(c tgbotapi.Chattable, done func(tgbotapi.Message, error)) {
+	c, priority, ok := botctx.Unwrap(c)
+
+	chatID := chattableChatID(c)
+	if !ok {
+		priority = chattablePriority(c, chatID)
+	}
+
+	// The chat's turn is reserved here, the request waits for it in the queue and not in the caller.
+	ready := time.Now()
+	switch {
+	case chatID > 0:
+		ready = ready.Add(sender.chat.Reserve(chatID))
+	case chatID < 0:
+		ready = ready.Add(sender.group.Reserve(chatID))
+	}
+
+	sender.mu.Lock()
+	if sender.stopped {
+		sender.mu.Unlock()
+		done(tgbotapi.Message{}, botctx.ErrSenderStopped)
+		return
+	}
+
+	sender.seq++
+	sender.queue = append(sender.queue, &sendJob{
+		chattable: c,
+		chatID:    chatID,
+		priority:  priority,
+		ready:     ready,
+		seq:       sender.seq,
+		done:      done,
+	})
+	sender.mu.Unlock()
+
+	metrics.TelegramSendQueued.Inc()
+	sender.notify()
+}
+
+func (sender *Sender) notify() {
+	select {
+	case sender.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (sender *Sender) work() {
+	defer sender.done.Done()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	stop := sender.stop
+	for {
+		job, wait, empty := sender.next(time.Now())
+		if job != nil {
+			metrics.TelegramSendQueued.Dec()
+
+			message, err := sender.send(job.chattable)
+
+			sender.mu.Lock()
+			delete(sender.sending, job.chatID)
+			sender.mu.Unlock()
+
+			job.done(message, err)
+
+			// Another worker may be waiting for a job this one could not take.
+			sender.notify()
+			continue
+		}
+
+		// Jobs of chats being sent to are left to the workers sending them, which take them next.
+		idle := empty || wait == math.MaxInt64
+		if idle && stop == nil {
+			return
+		}
+
+		if idle {
+			wait = time.Hour
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-sender.wake:
+		case <-timer.C:
+		case <-stop:
+			// Keep draining the queue, but leave once it is empty.
+			stop = nil
+		}
+	}
+}
+
+// next takes the most urgent job that is ready at now. Only the oldest job of a chat is considered,
+// and none while another job of the chat is being sent. Otherwise it returns how long until the
+// earliest job gets ready, math.MaxInt64 if all wait for the jobs being sent, or empty if there are none.
+func (sender *Sender) next(now time.Time) (job *sendJob, wait time.Duration, empty bool) {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	if len(sender.queue) == 0 {
+		return nil, 0, true
+	}
+
+	best := -1
+	wait = time.Duration(math.MaxInt64)
+	// The queue is in arrival order, so a chat seen before has an older job.
+	seen := make(map[int64]bool)
+	for i, candidate := range sender.queue {
+		if candidate.chatID != 0 {
+			if seen[candidate.chatID] || sender.sending[candidate.chatID] {
+				continue
+			}
+			seen[candidate.chatID] = true
+		}
+
+		if candidate.ready.After(now) {
+			if d := candidate.ready.Sub(now); d < wait {
+				wait = d
+			}
+			continue
+		}
+
+		if best < 0 || candidate.before(sender.queue[best]) {
+			best = i
+		}
+	}
+
+	if best < 0 {
+		return nil, wait, false
+	}
+
+	job = sender.queue[best]
+	sender.queue = append(sender.queue[:best], sender.queue[best+1:]...)
+
+	if job.chatID != 0 {
+		sender.sending[job.chatID] = true
+	}
+
+	return job, 0, false
+}
+
+func (sender *Sender) send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	for attempt := 0; ; attempt++ {
+		time.Sleep(sender.global.Reserve(0))
+
+		message, err := sender.api.Send(c)
+
+		var apiErr *tgbotapi.Error
+		if !errors.As(err, &apiErr) || apiErr.Code != http.StatusTooManyRequests || attempt >= sender.retries {
+			return message, err
+		}
+
+		metrics.TelegramSendRetries.Inc()
+
+		retryAfter := time.Duration(apiErr.RetryAfter) * time.Second
+		if retryAfter <= 0 {
+			retryAfter = time.Second
+		}
+		time.Sleep(retryAfter)
+	}
+}
+
+func chattableChatID(c tgbotapi.Chattable) int64 {
+	switch c := c.(type) {
+	case tgbotapi.MessageConfig:
+		return c.ChatID
+	case tgbotapi.PhotoConfig:
+		return c.ChatID
+	case tgbotapi.DocumentConfig:
+		return c.ChatID
+	case tgbotapi.EditMessageTextConfig:
+		return c.ChatID
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		return c.ChatID
+	}
+
+	return 0
+}
+
+func chattablePriority(c tgbotapi.Chattable, chatID int64) botctx.Priority {
+	if chatID > 0 {
+		return botctx.PriorityHigh
+	}
+
+	if _, ok := c.(tgbotapi.PhotoConfig); ok {
+		return botctx.PriorityLow
+	}
+
+	return botctx.PriorityNormal
+}
+
+type sendResult struct {
+	message tgbotapi.Message
+	err     error
+}
+
+type sendJob struct {
+	chattable tgbotapi.Chattable
+	chatID    int64
+	priority  botctx.Priority
+	// ready is when the chat's rate limit lets the job be sent.
+	ready time.Time
+	seq   uint64
+	done  func(tgbotapi.Message, error)
+}
+
+// before orders ready jobs by priority, then by arrival.
+func (job *sendJob) before(other *sendJob) bool {
+	if job.priority != other.priority {
+		return job.priority > other.priority
+	}
+
+	return job.seq < other.seq
+}
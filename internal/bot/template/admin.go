@@ -0,0 +1,10 @@
+package template
+
+import "text/template"
+
+var AdminList = template.Must(template.New("admin_list").Parse(`
+Администраторы:
+{{ range .admins }}
+{{ .user_id }} — {{ .role }}
+{{- end }}
+`))
@@ -5,7 +5,19 @@ import "text/template"
 var ApproveCaptions = template.Must(template.New("approve_captions").Parse(`
 Подписей осталось: {{ .reviewed_count }} / {{ .total_disapproved_remained }}
 
+{{ if .top_text -}}
+Сверху: {{ .top_text }}
+Снизу: {{ .bottom_text }}
+{{- else -}}
 Текст: {{ .text }}
+{{- end }}
 Автор: {{ .author_id }}
 Дата создания: {{ .created_at }}
+{{- if .similar }}
+
+Похоже на:
+{{- range .similar }}
+— {{ . }}
+{{- end }}
+{{- end }}
 `))
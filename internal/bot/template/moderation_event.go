@@ -0,0 +1,14 @@
+package template
+
+import "text/template"
+
+var ModerationLog = template.Must(template.New("moderation_log").Parse(`
+Журнал модерации, страница {{ .page }}
+{{ range .events }}
+{{ .created_at }} — {{ .action }}
+Модератор: {{ .moderator_id }}
+Подпись: {{ .caption_text }}
+{{- if .reason }}
+Комментарий: {{ .reason }}
+{{- end }}
+{{ end }}`))
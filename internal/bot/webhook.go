@@ -0,0 +1,195 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"markoslav/internal/config"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	ModePolling = "polling"
+	ModeWebhook = "webhook"
+
+	// SecretTokenHeader is set by Telegram on every webhook request when a secret token is registered.
+	SecretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+	// MaxUpdateBytes limits webhook request bodies. An update holds at most a couple of messages of
+	// 4096 characters with their entities, so it stays far below this.
+	MaxUpdateBytes = 1 << 20
+)
+
+type webhook struct {
+	api     *tgbotapi.BotAPI
+	conf    config.Webhook
+	server  *http.Server
+	updates chan tgbotapi.Update
+}
+
+func newWebhook(api *tgbotapi.BotAPI, conf config.Webhook) (*webhook, error) {
+	if conf.URL == "" {
+		return nil, errors.New("webhook url is required")
+	}
+
+	// Without a secret anyone who knows the URL could post fake updates.
+	if conf.Secret == "" {
+		return nil, errors.New("webhook secret is required")
+	}
+
+	if (conf.CertPath == "") != (conf.KeyPath == "") {
+		return nil, errors.New("webhook cert and key must be set together")
+	}
+
+	return &webhook{
+		api:     api,
+		conf:    conf,
+		updates: make(chan tgbotapi.Update, api.Buffer),
+	}, nil
+}
+
+// Start registers the webhook in Telegram and starts accepting updates on the listen address.
+func (webhook *webhook) Start() (tgbotapi.UpdatesChannel, error) {
+	link, err := url.Parse(webhook.conf.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	path := link.Path
+	if path == "" {
+		path = "/"
+	}
+	mux.HandleFunc(path, webhook.handle)
+
+	webhook.server = &http.Server{
+		Addr:              webhook.conf.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if webhook.conf.CertPath != "" {
+		cert, err := tls.LoadX509KeyPair(webhook.conf.CertPath, webhook.conf.KeyPath)
+		if err != nil {
+			return nil, err
+		}
+
+		webhook.server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+
+	// Listen before registering the webhook, so that Telegram is never pointed at a dead address.
+	listener, err := net.Listen("tcp", webhook.conf.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	go webhook.serve(listener)
+
+	if err = webhook.register(link); err != nil {
+		webhook.server.Close()
+		return nil, err
+	}
+
+	return webhook.updates, nil
+}
+
+// Stop removes the webhook from Telegram and closes the listener.
+func (webhook *webhook) Stop(ctx context.Context) {
+	if webhook.server == nil {
+		return
+	}
+
+	if _, err := webhook.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		log.Printf("delete webhook: %s", err)
+	}
+
+	// Shutdown waits for running handlers, so nothing writes to the channel after it is closed.
+	if err := webhook.server.Shutdown(ctx); err != nil {
+		log.Printf("webhook server: %s", err)
+		return
+	}
+
+	close(webhook.updates)
+}
+
+func (webhook *webhook) serve(listener net.Listener) {
+	log.Printf("webhook listening on %s", webhook.conf.Addr)
+
+	var err error
+	if webhook.server.TLSConfig != nil {
+		err = webhook.server.ServeTLS(listener, "", "")
+	} else {
+		err = webhook.server.Serve(listener)
+	}
+
+	// The webhook stays registered in Telegram, a bot that silently stopped receiving updates is worse than none.
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("webhook server: %s", err)
+	}
+}
+
+// register calls setWebhook directly because tgbotapi.WebhookConfig has no secret_token field.
+func (webhook *webhook) register(link *url.URL) error {
+	params := make(tgbotapi.Params)
+	params["url"] = link.String()
+	params.AddNonEmpty("secret_token", webhook.conf.Secret)
+
+	var (
+		response *tgbotapi.APIResponse
+		err      error
+	)
+	if webhook.conf.CertPath != "" {
+		response, err = webhook.api.UploadFiles("setWebhook", params, []tgbotapi.RequestFile{{
+			Name: "certificate",
+			Data: tgbotapi.FilePath(webhook.conf.CertPath),
+		}})
+	} else {
+		response, err = webhook.api.MakeRequest("setWebhook", params)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !response.Ok {
+		return fmt.Errorf("set webhook: %s", response.Description)
+	}
+
+	return nil
+}
+
+func (webhook *webhook) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	secret := r.Header.Get(SecretTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(webhook.conf.Secret)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var update tgbotapi.Update
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxUpdateBytes)).Decode(&update); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	webhook.updates <- update
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -3,11 +3,21 @@ package config
 import (
 	"github.com/ilyakaznacheev/cleanenv"
 	"log"
+	"time"
 )
 
 type Config struct {
+	App      App
 	Postgres Postgres
 	Bot      Bot
+	Caption  Caption
+	Download Download
+	Image    Image
+	HTTP     HTTP
+}
+
+type App struct {
+	ShutdownTimeout time.Duration `env:"APP_SHUTDOWN_TIMEOUT" env-default:"30s"`
 }
 
 type Postgres struct {
@@ -19,9 +29,82 @@ type Postgres struct {
 }
 
 type Bot struct {
-	Debug     bool    `env:"BOT_DEBUG"`
-	Token     string  `env:"BOT_TOKEN" env-required:"true"`
-	AdminList []int64 `env:"BOT_ADMIN_LIST" env-required:"true"`
+	Debug         bool          `env:"BOT_DEBUG"`
+	Token         string        `env:"BOT_TOKEN" env-required:"true"`
+	AdminList     []int64       `env:"BOT_ADMIN_LIST" env-required:"true"`
+	AdminCacheTTL time.Duration `env:"BOT_ADMIN_CACHE_TTL" env-default:"1m"`
+
+	SuggestLimit       int           `env:"BOT_SUGGEST_LIMIT" env-default:"5"`
+	SuggestLimitPeriod time.Duration `env:"BOT_SUGGEST_LIMIT_PERIOD" env-default:"1h"`
+
+	CustomCaptionLimit       int           `env:"BOT_CUSTOM_CAPTION_LIMIT" env-default:"10"`
+	CustomCaptionLimitPeriod time.Duration `env:"BOT_CUSTOM_CAPTION_LIMIT_PERIOD" env-default:"10m"`
+
+	Workers      int           `env:"BOT_WORKERS" env-default:"4"`
+	QueueSize    int           `env:"BOT_QUEUE_SIZE" env-default:"100"`
+	QueueTimeout time.Duration `env:"BOT_QUEUE_TIMEOUT" env-default:"5s"`
+
+	Mode    string `env:"BOT_MODE" env-default:"polling"`
+	Webhook Webhook
+	Backlog Backlog
+	Send    Send
+}
+
+// Send limits are in messages per second.
+type Send struct {
+	GlobalRate float64 `env:"BOT_SEND_GLOBAL_RATE" env-default:"30"`
+	ChatRate   float64 `env:"BOT_SEND_CHAT_RATE" env-default:"1"`
+	GroupRate  float64 `env:"BOT_SEND_GROUP_RATE" env-default:"0.33"`
+	Retries    int     `env:"BOT_SEND_RETRIES" env-default:"3"`
+}
+
+type Backlog struct {
+	MaxAge       time.Duration `env:"BOT_BACKLOG_MAX_AGE" env-default:"1m"`
+	KeepCommands bool          `env:"BOT_BACKLOG_KEEP_COMMANDS" env-default:"true"`
+	KeepPrivate  bool          `env:"BOT_BACKLOG_KEEP_PRIVATE" env-default:"true"`
+}
+
+type Webhook struct {
+	Addr     string `env:"BOT_WEBHOOK_ADDR" env-default:":8443"`
+	URL      string `env:"BOT_WEBHOOK_URL"`
+	Secret   string `env:"BOT_WEBHOOK_SECRET"`
+	CertPath string `env:"BOT_WEBHOOK_CERT_PATH"`
+	KeyPath  string `env:"BOT_WEBHOOK_KEY_PATH"`
+}
+
+type Caption struct {
+	MinLength     int    `env:"CAPTION_MIN_LENGTH" env-default:"2"`
+	MaxLength     int    `env:"CAPTION_MAX_LENGTH" env-default:"200"`
+	MaxLines      int    `env:"CAPTION_MAX_LINES" env-default:"3"`
+	AllowLinks    bool   `env:"CAPTION_ALLOW_LINKS" env-default:"false"`
+	AllowMentions bool   `env:"CAPTION_ALLOW_MENTIONS" env-default:"false"`
+	BlocklistPath string `env:"CAPTION_BLOCKLIST_PATH"`
+
+	SimilarityThreshold float64 `env:"CAPTION_SIMILARITY_THRESHOLD" env-default:"0.5"`
+}
+
+type Download struct {
+	Timeout       time.Duration `env:"DOWNLOAD_TIMEOUT" env-default:"30s"`
+	Retries       int           `env:"DOWNLOAD_RETRIES" env-default:"3"`
+	RetryDelay    time.Duration `env:"DOWNLOAD_RETRY_DELAY" env-default:"500ms"`
+	MaxBytes      int64         `env:"DOWNLOAD_MAX_BYTES" env-default:"20971520"`
+	CacheMaxBytes int64         `env:"DOWNLOAD_CACHE_MAX_BYTES" env-default:"134217728"`
+}
+
+type Image struct {
+	// Format is jpeg or png, WebP is not supported.
+	Format       string `env:"IMAGE_FORMAT" env-default:"jpeg"`
+	JPEGQuality  int    `env:"IMAGE_JPEG_QUALITY" env-default:"85"`
+	MaxDimension int    `env:"IMAGE_MAX_DIMENSION" env-default:"1280"`
+	Placement    string `env:"IMAGE_PLACEMENT" env-default:"smart"`
+
+	TextStyle       string  `env:"IMAGE_TEXT_STYLE" env-default:"auto"`
+	BackdropOpacity float64 `env:"IMAGE_BACKDROP_OPACITY" env-default:"0.55"`
+}
+
+type HTTP struct {
+	Addr  string `env:"HTTP_ADDR" env-default:":8080"`
+	Token string `env:"HTTP_TOKEN"`
 }
 
 func New() Config {
@@ -33,3 +116,20 @@ func New() Config {
 
 	return conf
 }
+
+// Transfer is the part of Config used by the import and export commands, which run without the bot.
+type Transfer struct {
+	Postgres Postgres
+	Caption  Caption
+	Image    Image
+}
+
+func NewTransfer() Transfer {
+	var conf Transfer
+	err := cleanenv.ReadEnv(&conf)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return conf
+}
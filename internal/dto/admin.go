@@ -0,0 +1,9 @@
+package dto
+
+import "markoslav/internal/model"
+
+type AddAdmin struct {
+	UserID  int64
+	Role    model.AdminRole
+	AddedBy int64
+}
@@ -0,0 +1,7 @@
+package dto
+
+type CreateBan struct {
+	UserID   int64
+	Reason   string
+	BannedBy int64
+}
@@ -5,9 +5,35 @@ import "github.com/google/uuid"
 type CreateCaption struct {
 	Text     string
 	AuthorID int64
+	Approved bool
+}
+
+// CustomCaption is user-supplied text drawn on a picture without being saved.
+type CustomCaption struct {
+	Text     string
+	AuthorID int64
+}
+
+type ImportCaptionsResult struct {
+	Inserted   int
+	Duplicates int
+	Invalid    int
 }
 
 type UpdateCaption struct {
 	ID       uuid.UUID
-	Approved bool
+	Text     *string
+	Approved *bool
+}
+
+type ModerateCaption struct {
+	ID          uuid.UUID
+	ModeratorID int64
+	Reason      string
+}
+
+type EditCaption struct {
+	ID          uuid.UUID
+	Text        string
+	ModeratorID int64
 }
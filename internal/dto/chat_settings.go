@@ -0,0 +1,12 @@
+package dto
+
+import "markoslav/internal/model"
+
+type UpdateChatSettings struct {
+	ChatID      int64
+	ImageFormat *model.ImageFormat
+	TextStyle   *model.TextStyle
+
+	CustomCaptionsDisabled *bool
+	UpdatedBy              int64
+}
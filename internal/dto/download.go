@@ -0,0 +1,11 @@
+package dto
+
+type Download struct {
+	URL string
+	// Key identifies the file in the image cache, e.g. PhotoSize.FileUniqueID; empty disables caching.
+	Key string
+	// Size is the file size reported by Telegram, e.g. PhotoSize.FileSize; 0 if unknown.
+	Size int
+	// MaxBytes overrides the configured download limit when positive.
+	MaxBytes int64
+}
@@ -0,0 +1,12 @@
+package dto
+
+import "markoslav/internal/model"
+
+type EncodedImage struct {
+	Data   []byte
+	Format model.ImageFormat
+}
+
+type DrawOptions struct {
+	TextStyle model.TextStyle
+}
@@ -0,0 +1,14 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"markoslav/internal/model"
+)
+
+type CreateModerationEvent struct {
+	CaptionID   uuid.UUID
+	CaptionText string
+	ModeratorID int64
+	Action      model.ModerationAction
+	Reason      string
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "markoslav/internal/model"
+
+type CheckPermission struct {
+	UserID     int64
+	ChatID     int64
+	Permission model.Permission
+}
@@ -0,0 +1,14 @@
+package dto
+
+import "github.com/google/uuid"
+
+type RenderedImageKey struct {
+	SourceFileUniqueID string
+	CaptionID          uuid.UUID
+	Style              string
+}
+
+type SaveRenderedImage struct {
+	RenderedImageKey
+	FileID string
+}
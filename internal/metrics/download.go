@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"context"
+	"image"
+	"markoslav/internal/dto"
+	"markoslav/internal/service"
+	"time"
+)
+
+type downloadService struct {
+	next service.DownloadService
+}
+
+// NewDownloadService records latency and failures of source image downloads of the wrapped service.
+func NewDownloadService(next service.DownloadService) service.DownloadService {
+	return &downloadService{next: next}
+}
+
+func (service *downloadService) Download(ctx context.Context, request dto.Download) ([]byte, error) {
+	return service.next.Download(ctx, request)
+}
+
+func (service *downloadService) DownloadImage(ctx context.Context, request dto.Download) (image.Image, error) {
+	start := time.Now()
+
+	img, err := service.next.DownloadImage(ctx, request)
+	ImageFetchDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		ImageFetchFailures.Inc()
+	}
+
+	return img, err
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"context"
+	"image"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/service"
+	"time"
+)
+
+type imageService struct {
+	next service.ImageService
+}
+
+// NewImageService records drawing duration and image size of the wrapped service.
+func NewImageService(next service.ImageService) service.ImageService {
+	return &imageService{next: next}
+}
+
+func (service *imageService) Draw(
+	ctx context.Context, caption model.Caption, img image.Image, options dto.DrawOptions,
+) (image.Image, error) {
+	bounds := img.Bounds()
+	ImageDrawPixels.Observe(float64(bounds.Dx() * bounds.Dy()))
+
+	start := time.Now()
+	defer func() {
+		ImageDrawDuration.Observe(time.Since(start).Seconds())
+	}()
+
+	return service.next.Draw(ctx, caption, img, options)
+}
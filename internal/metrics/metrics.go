@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "markoslav"
+
+var (
+	UpdatesDispatched = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "updates_dispatched_total",
+		Help:      "Telegram updates dispatched, by the handler that processed them.",
+	}, []string{"handler"})
+
+	UpdateDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "update_duration_seconds",
+		Help:      "Time spent processing a Telegram update, by handler.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"handler"})
+
+	UpdatesSkipped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "updates_skipped_total",
+		Help:      "Stale Telegram updates skipped by the backlog policy.",
+	})
+
+	UpdatesQueued = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "updates_queued",
+		Help:      "Telegram updates waiting in worker queues.",
+	})
+
+	UpdatesDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "updates_dropped_total",
+		Help:      "Telegram updates dropped because a worker queue stayed full.",
+	})
+
+	UpdateQueueWait = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "update_queue_wait_seconds",
+		Help:      "Time spent waiting for room in a full worker queue.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	})
+
+	ImageDrawDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "image_draw_duration_seconds",
+		Help:      "Time spent drawing a caption on an image.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	})
+
+	ImageDrawPixels = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "image_draw_pixels",
+		Help:      "Size of images captions are drawn on, in pixels.",
+		Buckets:   prometheus.ExponentialBuckets(64*64, 4, 10),
+	})
+
+	ImageFetchDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "image_fetch_duration_seconds",
+		Help:      "Time spent downloading source images from Telegram.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	})
+
+	ImageFetchFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "image_fetch_failures_total",
+		Help:      "Failed source image downloads.",
+	})
+
+	StorageQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_query_duration_seconds",
+		Help:      "Storage method latency.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"storage", "method"})
+
+	StorageQueryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_query_errors_total",
+		Help:      "Storage method calls failed with an internal error.",
+	}, []string{"storage", "method"})
+
+	TelegramRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_requests_total",
+		Help:      "Requests to the Telegram Bot API, by method and HTTP status code.",
+	}, []string{"method", "code"})
+
+	TelegramSendQueued = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "telegram_send_queued",
+		Help:      "Outgoing Telegram messages waiting to be sent.",
+	})
+
+	TelegramSendRetries = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_send_retries_total",
+		Help:      "Outgoing Telegram messages retried after a 429 response.",
+	})
+
+	TelegramRequestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_request_errors_total",
+		Help:      "Failed requests to the Telegram Bot API, by method.",
+	}, []string{"method"})
+)
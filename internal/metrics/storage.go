@@ -0,0 +1,227 @@
+package metrics
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/storage"
+	"markoslav/pkg/apperror"
+	"markoslav/pkg/filter"
+	"net/http"
+	"time"
+)
+
+func observeQuery(storage string, method string, start time.Time, err error) {
+	StorageQueryDuration.WithLabelValues(storage, method).Observe(time.Since(start).Seconds())
+
+	if err != nil && apperror.HTTPStatus(err) >= http.StatusInternalServerError {
+		StorageQueryErrors.WithLabelValues(storage, method).Inc()
+	}
+}
+
+type captionStorage struct {
+	next storage.CaptionStorage
+}
+
+func NewCaptionStorage(next storage.CaptionStorage) storage.CaptionStorage {
+	return &captionStorage{next: next}
+}
+
+func (s *captionStorage) Create(ctx context.Context, caption model.Caption) (err error) {
+	defer func(start time.Time) { observeQuery("caption", "Create", start, err) }(time.Now())
+
+	return s.next.Create(ctx, caption)
+}
+
+func (s *captionStorage) CreateMany(ctx context.Context, captions []model.Caption) (_ int, err error) {
+	defer func(start time.Time) { observeQuery("caption", "CreateMany", start, err) }(time.Now())
+
+	return s.next.CreateMany(ctx, captions)
+}
+
+func (s *captionStorage) GetByID(ctx context.Context, captionID uuid.UUID) (_ model.Caption, err error) {
+	defer func(start time.Time) { observeQuery("caption", "GetByID", start, err) }(time.Now())
+
+	return s.next.GetByID(ctx, captionID)
+}
+
+func (s *captionStorage) GetRandom(ctx context.Context) (_ model.Caption, err error) {
+	defer func(start time.Time) { observeQuery("caption", "GetRandom", start, err) }(time.Now())
+
+	return s.next.GetRandom(ctx)
+}
+
+func (s *captionStorage) ExistsByNormalizedText(ctx context.Context, normalizedText string) (_ bool, err error) {
+	defer func(start time.Time) { observeQuery("caption", "ExistsByNormalizedText", start, err) }(time.Now())
+
+	return s.next.ExistsByNormalizedText(ctx, normalizedText)
+}
+
+func (s *captionStorage) Select(ctx context.Context, count int, offset int, options filter.Options) (_ []model.Caption, err error) {
+	defer func(start time.Time) { observeQuery("caption", "Select", start, err) }(time.Now())
+
+	return s.next.Select(ctx, count, offset, options)
+}
+
+func (s *captionStorage) SelectSimilar(ctx context.Context, caption model.Caption, threshold float64, count int) (_ []model.Caption, err error) {
+	defer func(start time.Time) { observeQuery("caption", "SelectSimilar", start, err) }(time.Now())
+
+	return s.next.SelectSimilar(ctx, caption, threshold, count)
+}
+
+func (s *captionStorage) Update(ctx context.Context, caption model.Caption) (err error) {
+	defer func(start time.Time) { observeQuery("caption", "Update", start, err) }(time.Now())
+
+	return s.next.Update(ctx, caption)
+}
+
+func (s *captionStorage) Delete(ctx context.Context, captionID uuid.UUID) (err error) {
+	defer func(start time.Time) { observeQuery("caption", "Delete", start, err) }(time.Now())
+
+	return s.next.Delete(ctx, captionID)
+}
+
+type moderationEventStorage struct {
+	next storage.ModerationEventStorage
+}
+
+func NewModerationEventStorage(next storage.ModerationEventStorage) storage.ModerationEventStorage {
+	return &moderationEventStorage{next: next}
+}
+
+func (s *moderationEventStorage) Create(ctx context.Context, event model.ModerationEvent) (err error) {
+	defer func(start time.Time) { observeQuery("moderation_event", "Create", start, err) }(time.Now())
+
+	return s.next.Create(ctx, event)
+}
+
+func (s *moderationEventStorage) Select(ctx context.Context, count int, offset int, options filter.Options) (_ []model.ModerationEvent, err error) {
+	defer func(start time.Time) { observeQuery("moderation_event", "Select", start, err) }(time.Now())
+
+	return s.next.Select(ctx, count, offset, options)
+}
+
+type adminStorage struct {
+	next storage.AdminStorage
+}
+
+func NewAdminStorage(next storage.AdminStorage) storage.AdminStorage {
+	return &adminStorage{next: next}
+}
+
+func (s *adminStorage) Save(ctx context.Context, admin model.Admin) (err error) {
+	defer func(start time.Time) { observeQuery("admin", "Save", start, err) }(time.Now())
+
+	return s.next.Save(ctx, admin)
+}
+
+func (s *adminStorage) GetByUserID(ctx context.Context, userID int64) (_ model.Admin, err error) {
+	defer func(start time.Time) { observeQuery("admin", "GetByUserID", start, err) }(time.Now())
+
+	return s.next.GetByUserID(ctx, userID)
+}
+
+func (s *adminStorage) Select(ctx context.Context) (_ []model.Admin, err error) {
+	defer func(start time.Time) { observeQuery("admin", "Select", start, err) }(time.Now())
+
+	return s.next.Select(ctx)
+}
+
+func (s *adminStorage) Delete(ctx context.Context, userID int64) (_ bool, err error) {
+	defer func(start time.Time) { observeQuery("admin", "Delete", start, err) }(time.Now())
+
+	return s.next.Delete(ctx, userID)
+}
+
+type banStorage struct {
+	next storage.BanStorage
+}
+
+func NewBanStorage(next storage.BanStorage) storage.BanStorage {
+	return &banStorage{next: next}
+}
+
+func (s *banStorage) Save(ctx context.Context, ban model.Ban) (err error) {
+	defer func(start time.Time) { observeQuery("ban", "Save", start, err) }(time.Now())
+
+	return s.next.Save(ctx, ban)
+}
+
+func (s *banStorage) ExistsByUserID(ctx context.Context, userID int64) (_ bool, err error) {
+	defer func(start time.Time) { observeQuery("ban", "ExistsByUserID", start, err) }(time.Now())
+
+	return s.next.ExistsByUserID(ctx, userID)
+}
+
+func (s *banStorage) Delete(ctx context.Context, userID int64) (_ bool, err error) {
+	defer func(start time.Time) { observeQuery("ban", "Delete", start, err) }(time.Now())
+
+	return s.next.Delete(ctx, userID)
+}
+
+type updateOffsetStorage struct {
+	next storage.UpdateOffsetStorage
+}
+
+func NewUpdateOffsetStorage(next storage.UpdateOffsetStorage) storage.UpdateOffsetStorage {
+	return &updateOffsetStorage{next: next}
+}
+
+func (s *updateOffsetStorage) Get(ctx context.Context) (_ model.UpdateOffset, err error) {
+	defer func(start time.Time) { observeQuery("update_offset", "Get", start, err) }(time.Now())
+
+	return s.next.Get(ctx)
+}
+
+func (s *updateOffsetStorage) Save(ctx context.Context, updateID int) (err error) {
+	defer func(start time.Time) { observeQuery("update_offset", "Save", start, err) }(time.Now())
+
+	return s.next.Save(ctx, updateID)
+}
+
+type renderedImageStorage struct {
+	next storage.RenderedImageStorage
+}
+
+func NewRenderedImageStorage(next storage.RenderedImageStorage) storage.RenderedImageStorage {
+	return &renderedImageStorage{next: next}
+}
+
+func (s *renderedImageStorage) Save(ctx context.Context, renderedImage model.RenderedImage) (err error) {
+	defer func(start time.Time) { observeQuery("rendered_image", "Save", start, err) }(time.Now())
+
+	return s.next.Save(ctx, renderedImage)
+}
+
+func (s *renderedImageStorage) Get(ctx context.Context, key dto.RenderedImageKey) (_ model.RenderedImage, err error) {
+	defer func(start time.Time) { observeQuery("rendered_image", "Get", start, err) }(time.Now())
+
+	return s.next.Get(ctx, key)
+}
+
+func (s *renderedImageStorage) DeleteByCaptionID(ctx context.Context, captionID uuid.UUID) (err error) {
+	defer func(start time.Time) { observeQuery("rendered_image", "DeleteByCaptionID", start, err) }(time.Now())
+
+	return s.next.DeleteByCaptionID(ctx, captionID)
+}
+
+type chatSettingsStorage struct {
+	next storage.ChatSettingsStorage
+}
+
+func NewChatSettingsStorage(next storage.ChatSettingsStorage) storage.ChatSettingsStorage {
+	return &chatSettingsStorage{next: next}
+}
+
+func (s *chatSettingsStorage) Save(ctx context.Context, settings model.ChatSettings) (err error) {
+	defer func(start time.Time) { observeQuery("chat_settings", "Save", start, err) }(time.Now())
+
+	return s.next.Save(ctx, settings)
+}
+
+func (s *chatSettingsStorage) GetByChatID(ctx context.Context, chatID int64) (_ model.ChatSettings, err error) {
+	defer func(start time.Time) { observeQuery("chat_settings", "GetByChatID", start, err) }(time.Now())
+
+	return s.next.GetByChatID(ctx, chatID)
+}
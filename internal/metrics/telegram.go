@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"net/http"
+	"path"
+	"strconv"
+)
+
+type telegramClient struct {
+	next tgbotapi.HTTPClient
+}
+
+// NewTelegramClient counts Bot API requests made through the wrapped client.
+func NewTelegramClient(next tgbotapi.HTTPClient) tgbotapi.HTTPClient {
+	return &telegramClient{next: next}
+}
+
+func (client *telegramClient) Do(req *http.Request) (*http.Response, error) {
+	// The last path segment is the API method, the bot token is never part of the label.
+	method := path.Base(req.URL.Path)
+
+	response, err := client.next.Do(req)
+	if err != nil {
+		TelegramRequests.WithLabelValues(method, "error").Inc()
+		TelegramRequestErrors.WithLabelValues(method).Inc()
+
+		return response, err
+	}
+
+	TelegramRequests.WithLabelValues(method, strconv.Itoa(response.StatusCode)).Inc()
+	if response.StatusCode != http.StatusOK {
+		TelegramRequestErrors.WithLabelValues(method).Inc()
+	}
+
+	return response, nil
+}
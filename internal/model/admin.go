@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type AdminRole string
+
+const (
+	AdminRoleNone      AdminRole = ""
+	AdminRoleOwner     AdminRole = "owner"
+	AdminRoleModerator AdminRole = "moderator"
+)
+
+func (role AdminRole) Valid() bool {
+	return role == AdminRoleOwner || role == AdminRoleModerator
+}
+
+type Admin struct {
+	UserID    int64     `db:"user_id"`
+	Role      AdminRole `db:"role"`
+	AddedBy   int64     `db:"added_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
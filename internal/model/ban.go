@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+type Ban struct {
+	UserID    int64     `db:"user_id"`
+	Reason    string    `db:"reason"`
+	BannedBy  int64     `db:"banned_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -6,9 +6,13 @@ import (
 )
 
 type Caption struct {
-	ID        uuid.UUID `db:"id"`
-	Text      string    `db:"text"`
-	AuthorID  int64     `db:"author_id"`
-	Approved  bool      `db:"approved"`
-	CreatedAt time.Time `db:"created_at"`
+	ID   uuid.UUID `db:"id" json:"id"`
+	Text string    `db:"text" json:"text"`
+	// TopText and BottomText are set for two-part captions, drawn at the top and the bottom of the image.
+	TopText        string    `db:"top_text" json:"top_text,omitempty"`
+	BottomText     string    `db:"bottom_text" json:"bottom_text,omitempty"`
+	NormalizedText string    `db:"normalized_text" json:"-"`
+	AuthorID       int64     `db:"author_id" json:"author_id"`
+	Approved       bool      `db:"approved" json:"approved"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
 }
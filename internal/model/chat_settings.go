@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+type ChatSettings struct {
+	ChatID      int64       `db:"chat_id"`
+	ImageFormat ImageFormat `db:"image_format"`
+	TextStyle   TextStyle   `db:"text_style"`
+	// CustomCaptionsDisabled forbids drawing user-supplied text in the chat.
+	CustomCaptionsDisabled bool      `db:"custom_captions_disabled"`
+	UpdatedBy              int64     `db:"updated_by"`
+	UpdatedAt              time.Time `db:"updated_at"`
+}
@@ -0,0 +1,42 @@
+package model
+
+// ImageFormat is the format captioned photos are sent in. WebP is not one of them: Go has no WebP
+// encoder without cgo, and Telegram recompresses photos to JPEG anyway.
+type ImageFormat string
+
+const (
+	// ImageFormatDefault means the format configured for the whole bot.
+	ImageFormatDefault ImageFormat = ""
+	ImageFormatJPEG    ImageFormat = "jpeg"
+	ImageFormatPNG     ImageFormat = "png"
+)
+
+func (format ImageFormat) Valid() bool {
+	return format == ImageFormatJPEG || format == ImageFormatPNG
+}
+
+// Extension returns the file name extension of the format, including the dot.
+func (format ImageFormat) Extension() string {
+	if format == ImageFormatJPEG {
+		return ".jpg"
+	}
+
+	return "." + string(format)
+}
+
+type TextStyle string
+
+const (
+	// TextStyleDefault means the text style configured for the whole bot.
+	TextStyleDefault TextStyle = ""
+	// TextStyleClassic is white text with a black shadow.
+	TextStyleClassic TextStyle = "classic"
+	// TextStyleAuto is white or black text, whichever contrasts more with the image under it.
+	TextStyleAuto TextStyle = "auto"
+	// TextStyleBackdrop is white text on a semi-transparent dark rounded backdrop.
+	TextStyleBackdrop TextStyle = "backdrop"
+)
+
+func (style TextStyle) Valid() bool {
+	return style == TextStyleClassic || style == TextStyleAuto || style == TextStyleBackdrop
+}
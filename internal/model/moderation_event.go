@@ -0,0 +1,25 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type ModerationAction string
+
+const (
+	ModerationActionApprove ModerationAction = "approve"
+	ModerationActionReject  ModerationAction = "reject"
+	ModerationActionEdit    ModerationAction = "edit"
+	ModerationActionDelete  ModerationAction = "delete"
+)
+
+type ModerationEvent struct {
+	ID          uuid.UUID        `db:"id"`
+	CaptionID   uuid.UUID        `db:"caption_id"`
+	CaptionText string           `db:"caption_text"`
+	ModeratorID int64            `db:"moderator_id"`
+	Action      ModerationAction `db:"action"`
+	Reason      string           `db:"reason"`
+	CreatedAt   time.Time        `db:"created_at"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import "golang.org/x/exp/slices"
+
+type Permission string
+
+const (
+	PermissionSuggest      Permission = "suggest"
+	PermissionModerate     Permission = "moderate"
+	PermissionManageChat   Permission = "manage_chat"
+	PermissionManageAdmins Permission = "manage_admins"
+	PermissionBan          Permission = "ban"
+)
+
+var RolePermissions = map[AdminRole][]Permission{
+	AdminRoleNone: {
+		PermissionSuggest,
+	},
+	AdminRoleModerator: {
+		PermissionSuggest,
+		PermissionModerate,
+		PermissionBan,
+	},
+	AdminRoleOwner: {
+		PermissionSuggest,
+		PermissionModerate,
+		PermissionManageChat,
+		PermissionManageAdmins,
+		PermissionBan,
+	},
+}
+
+func (role AdminRole) Can(permission Permission) bool {
+	return slices.Contains(RolePermissions[role], permission)
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// DefaultImageStyle is the style of captions drawn with the default settings.
+const DefaultImageStyle = "default"
+
+// RenderedImage is a captioned photo already uploaded to Telegram, so it can be resent by file ID.
+type RenderedImage struct {
+	SourceFileUniqueID string    `db:"source_file_unique_id"`
+	CaptionID          uuid.UUID `db:"caption_id"`
+	Style              string    `db:"style"`
+	FileID             string    `db:"file_id"`
+	CreatedAt          time.Time `db:"created_at"`
+}
@@ -0,0 +1,8 @@
+package model
+
+import "time"
+
+type UpdateOffset struct {
+	LastUpdateID int       `db:"last_update_id"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"markoslav/pkg/apperror"
+	"net/http"
+	"strings"
+)
+
+// authorized rejects requests without a valid bearer token. An empty token disables access entirely.
+func authorized(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			writeError(w, r, apperror.Unauthorized.WithMessage("invalid token"))
+			return
+		}
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"markoslav/internal/dto"
+	"markoslav/internal/usecase"
+	"markoslav/pkg/apperror"
+	"markoslav/pkg/filter"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultCaptionsCount = 25
+	MaxCaptionsCount     = 100
+
+	// MaxRequestBytes limits JSON request bodies, which only carry a caption and a few fields.
+	MaxRequestBytes = 64 << 10
+)
+
+type createCaptionRequest struct {
+	Text     string `json:"text"`
+	AuthorID int64  `json:"author_id"`
+	Approved bool   `json:"approved"`
+}
+
+type moderateCaptionRequest struct {
+	ModeratorID int64  `json:"moderator_id"`
+	Reason      string `json:"reason"`
+}
+
+type CaptionHandler struct {
+	captionUsecase usecase.CaptionUsecase
+	adminUsecase   usecase.AdminUsecase
+	token          string
+}
+
+func NewCaptionHandler(captionUsecase usecase.CaptionUsecase, adminUsecase usecase.AdminUsecase, token string) *CaptionHandler {
+	return &CaptionHandler{captionUsecase: captionUsecase, adminUsecase: adminUsecase, token: token}
+}
+
+func (handler *CaptionHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/api/captions", authorized(handler.token, handler.captions))
+	mux.HandleFunc("/api/captions/", authorized(handler.token, handler.caption))
+}
+
+// captions serves /api/captions.
+func (handler *CaptionHandler) captions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		handler.list(w, r)
+	case http.MethodPost:
+		handler.create(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// caption serves /api/captions/{id}, /api/captions/{id}/approve and /api/captions/{id}/reject.
+func (handler *CaptionHandler) caption(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/captions/"), "/"), "/")
+
+	captionID, err := uuid.Parse(parts[0])
+	if err != nil {
+		writeError(w, r, apperror.BadRequest.WithMessage("invalid caption id"))
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		handler.get(w, r, captionID)
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		handler.delete(w, r, captionID)
+	case len(parts) == 2 && parts[1] == "approve" && r.Method == http.MethodPost:
+		handler.approve(w, r, captionID)
+	case len(parts) == 2 && parts[1] == "reject" && r.Method == http.MethodPost:
+		handler.reject(w, r, captionID)
+	case len(parts) <= 2:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (handler *CaptionHandler) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	count, err := intParam(query.Get("count"), DefaultCaptionsCount)
+	if err != nil || count <= 0 || count > MaxCaptionsCount {
+		writeError(w, r, apperror.BadRequest.WithMessage("invalid count"))
+		return
+	}
+
+	offset, err := intParam(query.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		writeError(w, r, apperror.BadRequest.WithMessage("invalid offset"))
+		return
+	}
+
+	options := filter.NewOptions()
+
+	if value := query.Get("approved"); value != "" {
+		approved, err := strconv.ParseBool(value)
+		if err != nil {
+			writeError(w, r, apperror.BadRequest.WithMessage("invalid approved"))
+			return
+		}
+
+		options.Add("approved", approved, filter.OperatorEq)
+	}
+
+	if value := query.Get("author_id"); value != "" {
+		authorID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			writeError(w, r, apperror.BadRequest.WithMessage("invalid author_id"))
+			return
+		}
+
+		options.Add("author_id", authorID, filter.OperatorEq)
+	}
+
+	captions, err := handler.captionUsecase.Select(r.Context(), count, offset, options)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, captions)
+}
+
+func (handler *CaptionHandler) create(w http.ResponseWriter, r *http.Request) {
+	var request createCaptionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestBytes)).Decode(&request); err != nil {
+		writeError(w, r, apperror.BadRequest.WithMessage("invalid request body"))
+		return
+	}
+
+	caption, err := handler.captionUsecase.Add(r.Context(), dto.CreateCaption{
+		Text:     request.Text,
+		AuthorID: request.AuthorID,
+		Approved: request.Approved,
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, caption)
+}
+
+func (handler *CaptionHandler) get(w http.ResponseWriter, r *http.Request, captionID uuid.UUID) {
+	caption, err := handler.captionUsecase.GetByID(r.Context(), captionID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, caption)
+}
+
+func (handler *CaptionHandler) delete(w http.ResponseWriter, r *http.Request, captionID uuid.UUID) {
+	request, err := handler.decodeModerateRequest(w, r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	err = handler.captionUsecase.Delete(r.Context(), dto.ModerateCaption{
+		ID:          captionID,
+		ModeratorID: request.ModeratorID,
+		Reason:      request.Reason,
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *CaptionHandler) approve(w http.ResponseWriter, r *http.Request, captionID uuid.UUID) {
+	request, err := handler.decodeModerateRequest(w, r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	err = handler.captionUsecase.Approve(r.Context(), dto.ModerateCaption{
+		ID:          captionID,
+		ModeratorID: request.ModeratorID,
+		Reason:      request.Reason,
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	handler.get(w, r, captionID)
+}
+
+func (handler *CaptionHandler) reject(w http.ResponseWriter, r *http.Request, captionID uuid.UUID) {
+	request, err := handler.decodeModerateRequest(w, r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	err = handler.captionUsecase.Reject(r.Context(), dto.ModerateCaption{
+		ID:          captionID,
+		ModeratorID: request.ModeratorID,
+		Reason:      request.Reason,
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeModerateRequest reads a moderation body. The API token is shared, so the moderator is named
+// in the body and must be a bot admin.
+func (handler *CaptionHandler) decodeModerateRequest(w http.ResponseWriter, r *http.Request) (moderateCaptionRequest, error) {
+	var request moderateCaptionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestBytes)).Decode(&request); err != nil {
+		return request, apperror.BadRequest.WithMessage("invalid request body")
+	}
+
+	if request.ModeratorID == 0 {
+		return request, apperror.BadRequest.WithMessage("moderator_id is required")
+	}
+
+	role, err := handler.adminUsecase.GetRole(r.Context(), request.ModeratorID)
+	if err != nil {
+		return request, err
+	}
+
+	if !role.Valid() {
+		return request, apperror.Forbidden.WithMessage("moderator_id is not an admin")
+	}
+
+	return request, nil
+}
+
+func intParam(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	return strconv.Atoi(value)
+}
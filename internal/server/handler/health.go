@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"markoslav/internal/service"
+	"net/http"
+)
+
+type readinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+type HealthHandler struct {
+	healthService service.HealthService
+}
+
+func NewHealthHandler(healthService service.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: healthService}
+}
+
+func (handler *HealthHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", handler.health)
+	mux.HandleFunc("/readyz", handler.ready)
+}
+
+func (handler *HealthHandler) health(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (handler *HealthHandler) ready(w http.ResponseWriter, r *http.Request) {
+	response := readinessResponse{Status: "ok", Checks: make(map[string]string)}
+	status := http.StatusOK
+
+	for name, err := range handler.healthService.Ready(r.Context()) {
+		if err != nil {
+			response.Checks[name] = err.Error()
+			response.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+
+			continue
+		}
+
+		response.Checks[name] = "ok"
+	}
+
+	writeJSON(w, status, response)
+}
@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+type MetricsHandler struct{}
+
+func NewMetricsHandler() *MetricsHandler {
+	return &MetricsHandler{}
+}
+
+func (handler *MetricsHandler) Register(mux *http.ServeMux) {
+	mux.Handle("/metrics", promhttp.Handler())
+}
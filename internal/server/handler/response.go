@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"markoslav/pkg/apperror"
+	"net/http"
+)
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+
+	if value == nil {
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("write response: %s", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	apperr := apperror.As(err)
+	status := apperror.HTTPStatus(err)
+
+	if status >= http.StatusInternalServerError {
+		log.Printf("http: %s %s: %s", r.Method, r.URL.Path, err)
+	}
+
+	if apperr.Message == "" {
+		apperr.Message = apperror.UserMessage(err, r.Header.Get("Accept-Language"))
+	}
+
+	writeJSON(w, status, apperr)
+}
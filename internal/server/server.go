@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"markoslav/internal/config"
+	"net/http"
+	"time"
+)
+
+type Handler interface {
+	Register(mux *http.ServeMux)
+}
+
+type Server struct {
+	HTTP *http.Server
+	Mux  *http.ServeMux
+}
+
+func New(conf config.HTTP) *Server {
+	mux := http.NewServeMux()
+
+	return &Server{
+		HTTP: &http.Server{
+			Addr:              conf.Addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		Mux: mux,
+	}
+}
+
+func (server *Server) Handle(handlers ...Handler) *Server {
+	for _, handler := range handlers {
+		handler.Register(server.Mux)
+	}
+
+	return server
+}
+
+func (server *Server) Shutdown(ctx context.Context) error {
+	return server.HTTP.Shutdown(ctx)
+}
+
+func (server *Server) Run() {
+	log.Printf("http server listening on %s", server.HTTP.Addr)
+
+	if err := server.HTTP.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("http server: %s", err)
+	}
+}
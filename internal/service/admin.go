@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"golang.org/x/exp/slices"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/storage"
+	"markoslav/pkg/apperror"
+	"sync"
+	"time"
+)
+
+type AdminService interface {
+	Add(ctx context.Context, request dto.AddAdmin) (model.Admin, error)
+	Remove(ctx context.Context, userID int64) error
+
+	GetRole(ctx context.Context, userID int64) (model.AdminRole, error)
+
+	Select(ctx context.Context) ([]model.Admin, error)
+}
+
+type cachedRole struct {
+	role      model.AdminRole
+	expiresAt time.Time
+}
+
+type adminService struct {
+	storage storage.AdminStorage
+
+	// owners are configured through the environment and can't be changed at runtime.
+	owners []int64
+
+	cacheTTL time.Duration
+	cacheMu  sync.RWMutex
+	cache    map[int64]cachedRole
+}
+
+func NewAdminService(storage storage.AdminStorage, owners []int64, cacheTTL time.Duration) AdminService {
+	return &adminService{
+		storage:  storage,
+		owners:   owners,
+		cacheTTL: cacheTTL,
+		cache:    make(map[int64]cachedRole),
+	}
+}
+
+func (service *adminService) Add(ctx context.Context, request dto.AddAdmin) (model.Admin, error) {
+	if !request.Role.Valid() {
+		return model.Admin{}, apperror.BadRequest.WithMessage("unknown admin role")
+	}
+
+	if slices.Contains(service.owners, request.UserID) {
+		return model.Admin{}, apperror.Forbidden.WithMessage("bootstrap owner can't be changed")
+	}
+
+	admin := model.Admin{
+		UserID:    request.UserID,
+		Role:      request.Role,
+		AddedBy:   request.AddedBy,
+		CreatedAt: time.Now(),
+	}
+	err := service.storage.Save(ctx, admin)
+	if err != nil {
+		return model.Admin{}, err
+	}
+
+	service.invalidate(request.UserID)
+
+	return admin, nil
+}
+
+func (service *adminService) Remove(ctx context.Context, userID int64) error {
+	if slices.Contains(service.owners, userID) {
+		return apperror.Forbidden.WithMessage("bootstrap owner can't be removed")
+	}
+
+	deleted, err := service.storage.Delete(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	service.invalidate(userID)
+
+	if !deleted {
+		return apperror.NotFound.WithMessage("admin not found")
+	}
+
+	return nil
+}
+
+func (service *adminService) GetRole(ctx context.Context, userID int64) (model.AdminRole, error) {
+	if slices.Contains(service.owners, userID) {
+		return model.AdminRoleOwner, nil
+	}
+
+	service.cacheMu.RLock()
+	cached, ok := service.cache[userID]
+	service.cacheMu.RUnlock()
+
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.role, nil
+	}
+
+	role := model.AdminRoleNone
+
+	admin, err := service.storage.GetByUserID(ctx, userID)
+	if err != nil {
+		if _, ok = apperror.Is(err, apperror.NotFound); !ok {
+			return model.AdminRoleNone, err
+		}
+	} else {
+		role = admin.Role
+	}
+
+	service.cacheMu.Lock()
+	service.cache[userID] = cachedRole{role: role, expiresAt: time.Now().Add(service.cacheTTL)}
+	service.cacheMu.Unlock()
+
+	return role, nil
+}
+
+func (service *adminService) Select(ctx context.Context) ([]model.Admin, error) {
+	admins, err := service.storage.Select(ctx)
+	if err != nil {
+		return []model.Admin{}, err
+	}
+
+	owners := make([]model.Admin, 0, len(service.owners)+len(admins))
+	for _, userID := range service.owners {
+		owners = append(owners, model.Admin{UserID: userID, Role: model.AdminRoleOwner})
+	}
+
+	return append(owners, admins...), nil
+}
+
+func (service *adminService) invalidate(userID int64) {
+	service.cacheMu.Lock()
+	delete(service.cache, userID)
+	service.cacheMu.Unlock()
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/storage"
+	"markoslav/pkg/apperror"
+	"time"
+)
+
+type BanService interface {
+	Ban(ctx context.Context, request dto.CreateBan) (model.Ban, error)
+	Unban(ctx context.Context, userID int64) error
+
+	IsBanned(ctx context.Context, userID int64) (bool, error)
+}
+
+type banService struct {
+	storage storage.BanStorage
+}
+
+func NewBanService(storage storage.BanStorage) BanService {
+	return &banService{storage: storage}
+}
+
+func (service *banService) Ban(ctx context.Context, request dto.CreateBan) (model.Ban, error) {
+	ban := model.Ban{
+		UserID:    request.UserID,
+		Reason:    request.Reason,
+		BannedBy:  request.BannedBy,
+		CreatedAt: time.Now(),
+	}
+	err := service.storage.Save(ctx, ban)
+	if err != nil {
+		return model.Ban{}, err
+	}
+
+	return ban, nil
+}
+
+func (service *banService) Unban(ctx context.Context, userID int64) error {
+	deleted, err := service.storage.Delete(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return apperror.NotFound.WithMessage("ban not found")
+	}
+
+	return nil
+}
+
+func (service *banService) IsBanned(ctx context.Context, userID int64) (bool, error) {
+	return service.storage.ExistsByUserID(ctx, userID)
+}
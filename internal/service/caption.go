@@ -8,32 +8,58 @@ import (
 	"markoslav/internal/storage"
 	"markoslav/pkg/apperror"
 	"markoslav/pkg/filter"
+	"strings"
 	"time"
+	"unicode"
 )
 
 type CaptionService interface {
 	Create(ctx context.Context, request dto.CreateCaption) (model.Caption, error)
+	CreateMany(ctx context.Context, requests []dto.CreateCaption) (dto.ImportCaptionsResult, error)
 
 	GetByID(ctx context.Context, captionID uuid.UUID) (model.Caption, error)
 	GetRandom(ctx context.Context) (model.Caption, error)
 
 	Select(ctx context.Context, count int, offset int, options filter.Options) ([]model.Caption, error)
+	SelectSimilar(ctx context.Context, caption model.Caption, count int) ([]model.Caption, error)
 
 	Update(ctx context.Context, request dto.UpdateCaption) error
 
 	Delete(ctx context.Context, captionID uuid.UUID) error
+
+	// Parse checks the text against the same rules as new captions and splits two-part captions.
+	Parse(text string) (model.Caption, error)
 }
 
+// CaptionPartSeparator splits the text of a two-part caption into the top and the bottom part.
+const CaptionPartSeparator = "|"
+
 type captionService struct {
-	storage storage.CaptionStorage
+	storage              storage.CaptionStorage
+	renderedImageStorage storage.RenderedImageStorage
+	validator            CaptionValidator
+	similarityThreshold  float64
 }
 
-func NewCaptionService(storage storage.CaptionStorage) CaptionService {
-	return &captionService{storage: storage}
+func NewCaptionService(
+	storage storage.CaptionStorage,
+	renderedImageStorage storage.RenderedImageStorage,
+	validator CaptionValidator,
+	similarityThreshold float64,
+) CaptionService {
+	return &captionService{
+		storage: storage, renderedImageStorage: renderedImageStorage,
+		validator: validator, similarityThreshold: similarityThreshold,
+	}
 }
 
 func (service *captionService) Create(ctx context.Context, request dto.CreateCaption) (model.Caption, error) {
-	exists, err := service.storage.ExistsByText(ctx, request.Text)
+	caption, err := service.Parse(request.Text)
+	if err != nil {
+		return model.Caption{}, err
+	}
+
+	exists, err := service.storage.ExistsByNormalizedText(ctx, caption.NormalizedText)
 	if err != nil {
 		return model.Caption{}, err
 	}
@@ -42,13 +68,11 @@ func (service *captionService) Create(ctx context.Context, request dto.CreateCap
 		return model.Caption{}, apperror.AlreadyExists.WithMessage("caption already exists")
 	}
 
-	caption := model.Caption{
-		ID:        uuid.New(),
-		Text:      request.Text,
-		AuthorID:  request.AuthorID,
-		Approved:  false,
-		CreatedAt: time.Now(),
-	}
+	caption.ID = uuid.New()
+	caption.AuthorID = request.AuthorID
+	caption.Approved = request.Approved
+	caption.CreatedAt = time.Now()
+
 	err = service.storage.Create(ctx, caption)
 	if err != nil {
 		return model.Caption{}, err
@@ -57,6 +81,49 @@ func (service *captionService) Create(ctx context.Context, request dto.CreateCap
 	return caption, nil
 }
 
+func (service *captionService) CreateMany(ctx context.Context, requests []dto.CreateCaption) (dto.ImportCaptionsResult, error) {
+	var result dto.ImportCaptionsResult
+
+	seen := make(map[string]struct{}, len(requests))
+	captions := make([]model.Caption, 0, len(requests))
+
+	for _, request := range requests {
+		caption, err := service.Parse(request.Text)
+		if err != nil {
+			if _, ok := apperror.Is(err, apperror.BadRequest); ok {
+				result.Invalid++
+				continue
+			}
+
+			return result, err
+		}
+
+		if _, ok := seen[caption.NormalizedText]; ok {
+			result.Duplicates++
+			continue
+		}
+
+		seen[caption.NormalizedText] = struct{}{}
+
+		caption.ID = uuid.New()
+		caption.AuthorID = request.AuthorID
+		caption.Approved = request.Approved
+		caption.CreatedAt = time.Now()
+
+		captions = append(captions, caption)
+	}
+
+	inserted, err := service.storage.CreateMany(ctx, captions)
+	if err != nil {
+		return result, err
+	}
+
+	result.Inserted = inserted
+	result.Duplicates += len(captions) - inserted
+
+	return result, nil
+}
+
 func (service *captionService) GetByID(ctx context.Context, captionID uuid.UUID) (model.Caption, error) {
 	caption, err := service.storage.GetByID(ctx, captionID)
 	if err != nil {
@@ -84,19 +151,62 @@ func (service *captionService) Select(ctx context.Context, count int, offset int
 	return captions, nil
 }
 
+func (service *captionService) SelectSimilar(ctx context.Context, caption model.Caption, count int) ([]model.Caption, error) {
+	captions, err := service.storage.SelectSimilar(ctx, caption, service.similarityThreshold, count)
+	if err != nil {
+		return []model.Caption{}, err
+	}
+
+	return captions, nil
+}
+
 func (service *captionService) Update(ctx context.Context, request dto.UpdateCaption) error {
 	caption, err := service.GetByID(ctx, request.ID)
 	if err != nil {
 		return err
 	}
 
-	caption.Approved = request.Approved
+	if request.Text != nil {
+		var parsed model.Caption
+		parsed, err = service.Parse(*request.Text)
+		if err != nil {
+			return err
+		}
+
+		if parsed.NormalizedText != caption.NormalizedText {
+			var exists bool
+			exists, err = service.storage.ExistsByNormalizedText(ctx, parsed.NormalizedText)
+			if err != nil {
+				return err
+			}
+
+			if exists {
+				return apperror.AlreadyExists.WithMessage("caption already exists")
+			}
+		}
+
+		caption.Text = parsed.Text
+		caption.TopText = parsed.TopText
+		caption.BottomText = parsed.BottomText
+		caption.NormalizedText = parsed.NormalizedText
+	}
+
+	if request.Approved != nil {
+		caption.Approved = *request.Approved
+	}
 
 	err = service.storage.Update(ctx, caption)
 	if err != nil {
 		return err
 	}
 
+	// Photos rendered with the old text must not be resent.
+	if request.Text != nil {
+		if err = service.renderedImageStorage.DeleteByCaptionID(ctx, caption.ID); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -108,3 +218,58 @@ func (service *captionService) Delete(ctx context.Context, captionID uuid.UUID)
 
 	return nil
 }
+
+// normalizeCaptionText reduces text to the form used for duplicate detection:
+// lower case, "ё" replaced with "е", no punctuation and single spaces between words.
+func normalizeCaptionText(text string) string {
+	normalized := strings.Map(func(r rune) rune {
+		switch {
+		case r == 'ё':
+			return 'е'
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return r
+		case unicode.IsSpace(r), r == '|':
+			// The part separator counts as a space, so "a|b" and "a | b" are the same caption.
+			return ' '
+		default:
+			return -1
+		}
+	}, strings.ToLower(text))
+
+	// Captions of symbols only would all normalise to "" and be duplicates of each other.
+	if strings.TrimSpace(normalized) == "" {
+		normalized = strings.ToLower(text)
+	}
+
+	return strings.Join(strings.Fields(normalized), " ")
+}
+
+func (service *captionService) Parse(text string) (model.Caption, error) {
+	text, err := service.validator.Validate(text)
+	if err != nil {
+		return model.Caption{}, err
+	}
+
+	caption := model.Caption{Text: text}
+
+	top, bottom, found := strings.Cut(text, CaptionPartSeparator)
+	if found {
+		top, bottom = strings.TrimSpace(top), strings.TrimSpace(bottom)
+
+		if strings.Contains(bottom, CaptionPartSeparator) {
+			return model.Caption{}, apperror.BadRequest.WithMessage("Подпись может состоять не более чем из двух частей.")
+		}
+
+		if top == "" || bottom == "" {
+			return model.Caption{}, apperror.BadRequest.WithMessage("Обе части подписи, до и после «|», должны быть заполнены.")
+		}
+
+		caption.Text = top + " " + CaptionPartSeparator + " " + bottom
+		caption.TopText = top
+		caption.BottomText = bottom
+	}
+
+	caption.NormalizedText = normalizeCaptionText(caption.Text)
+
+	return caption, nil
+}
@@ -0,0 +1,25 @@
+package service
+
+import "testing"
+
+func TestNormalizeCaptionText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Ёлки,  Палки!", "елки палки"},
+		{"а|б", "а б"},
+		{"а | б", "а б"},
+		{"!!!", "!!!"},
+		{"?!  ?!", "?! ?!"},
+		{"😀 😀", "😀 😀"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := normalizeCaptionText(tt.text); got != tt.want {
+				t.Errorf("normalizeCaptionText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"markoslav/pkg/apperror"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// linkRegexp uses explicit guards instead of \b, which only knows ASCII word characters.
+	linkRegexp = regexp.MustCompile(
+		`(?i)(https?://|www\.|t\.me/|(^|[^\p{L}\d-])[\p{L}\d-]+\.(ru|com|org|net|me|io|su|рф)($|[^\p{L}\d]))`,
+	)
+	mentionRegexp = regexp.MustCompile(`(^|\s)@\w{3,}`)
+)
+
+type CaptionValidationConfig struct {
+	MinLength     int
+	MaxLength     int
+	MaxLines      int
+	AllowLinks    bool
+	AllowMentions bool
+	BlocklistPath string
+}
+
+// CaptionValidator normalises caption text and checks it against the configured rules.
+type CaptionValidator interface {
+	Validate(text string) (string, error)
+}
+
+type captionRule func(text string) (string, error)
+
+type captionValidator struct {
+	rules []captionRule
+}
+
+func NewCaptionValidator(config CaptionValidationConfig) (CaptionValidator, error) {
+	validator := &captionValidator{}
+
+	validator.rules = append(validator.rules, normalizeWhitespace, requireText)
+
+	if config.MinLength > 0 || config.MaxLength > 0 {
+		validator.rules = append(validator.rules, lengthRule(config.MinLength, config.MaxLength))
+	}
+
+	if config.MaxLines > 0 {
+		validator.rules = append(validator.rules, maxLinesRule(config.MaxLines))
+	}
+
+	if !config.AllowLinks {
+		validator.rules = append(validator.rules, rejectRule(linkRegexp, "Подпись не должна содержать ссылки."))
+	}
+
+	if !config.AllowMentions {
+		validator.rules = append(validator.rules, rejectRule(mentionRegexp, "Подпись не должна содержать упоминания."))
+	}
+
+	if config.BlocklistPath != "" {
+		words, err := readBlocklist(config.BlocklistPath)
+		if err != nil {
+			return nil, err
+		}
+
+		validator.rules = append(validator.rules, blocklistRule(words))
+	}
+
+	return validator, nil
+}
+
+func (validator *captionValidator) Validate(text string) (string, error) {
+	var err error
+	for _, rule := range validator.rules {
+		text, err = rule(text)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return text, nil
+}
+
+func normalizeWhitespace(text string) (string, error) {
+	lines := strings.Split(text, "\n")
+
+	normalized := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			normalized = append(normalized, line)
+		}
+	}
+
+	return strings.Join(normalized, "\n"), nil
+}
+
+func requireText(text string) (string, error) {
+	if text == "" {
+		return "", apperror.BadRequest.WithMessage("Подпись не должна быть пустой.")
+	}
+
+	return text, nil
+}
+
+func lengthRule(min int, max int) captionRule {
+	return func(text string) (string, error) {
+		length := utf8.RuneCountInString(text)
+
+		if min > 0 && length < min {
+			return "", apperror.BadRequest.WithMessage(fmt.Sprintf("Подпись слишком короткая, минимум символов: %d.", min))
+		}
+
+		if max > 0 && length > max {
+			return "", apperror.BadRequest.WithMessage(fmt.Sprintf("Подпись слишком длинная, максимум символов: %d.", max))
+		}
+
+		return text, nil
+	}
+}
+
+func maxLinesRule(max int) captionRule {
+	return func(text string) (string, error) {
+		if strings.Count(text, "\n")+1 > max {
+			return "", apperror.BadRequest.WithMessage(fmt.Sprintf("Подпись содержит слишком много строк, максимум: %d.", max))
+		}
+
+		return text, nil
+	}
+}
+
+func rejectRule(re *regexp.Regexp, message string) captionRule {
+	return func(text string) (string, error) {
+		if re.MatchString(text) {
+			return "", apperror.BadRequest.WithMessage(message)
+		}
+
+		return text, nil
+	}
+}
+
+func blocklistRule(words map[string]struct{}) captionRule {
+	return func(text string) (string, error) {
+		fields := strings.FieldsFunc(normalizeWord(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+
+		for _, field := range fields {
+			if _, ok := words[field]; ok {
+				return "", apperror.BadRequest.WithMessage("Подпись содержит недопустимые слова.")
+			}
+		}
+
+		return text, nil
+	}
+}
+
+func readBlocklist(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open blocklist: %w", err)
+	}
+	defer file.Close()
+
+	words := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := normalizeWord(strings.TrimSpace(scanner.Text()))
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+
+		words[word] = struct{}{}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read blocklist: %w", err)
+	}
+
+	return words, nil
+}
+
+func normalizeWord(word string) string {
+	return strings.ReplaceAll(strings.ToLower(word), "ё", "е")
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"markoslav/pkg/apperror"
+	"testing"
+)
+
+func TestLinkRegexp(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"зайди на сайт.рф", true},
+		{"кот.рф", true},
+		{"КОТ.РФ", true},
+		{"example.com", true},
+		{"смотри example.com, там всё", true},
+		{"my-site.ru/page", true},
+		{"https://пример", true},
+		{"www.что-то", true},
+		{"t.me/channel", true},
+		{"обычный текст", false},
+		{"конец.русский", false},
+		{"example.community", false},
+		{"число 3.14", false},
+		{"кот.рфы", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := linkRegexp.MatchString(tt.text); got != tt.want {
+				t.Errorf("linkRegexp.MatchString(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaptionValidatorLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  CaptionValidationConfig
+		text    string
+		want    string
+		message string
+	}{
+		{"normalizes whitespace", CaptionValidationConfig{MinLength: 2}, "  привет   мир \n\n", "привет мир", ""},
+		{"empty without minimum", CaptionValidationConfig{MaxLength: 10}, "   ", "", "Подпись не должна быть пустой."},
+		{"empty without limits", CaptionValidationConfig{}, " \n ", "", "Подпись не должна быть пустой."},
+		{"short", CaptionValidationConfig{MinLength: 3}, "аб", "", "Подпись слишком короткая, минимум символов: 3."},
+		{"long", CaptionValidationConfig{MaxLength: 3}, "абвг", "", "Подпись слишком длинная, максимум символов: 3."},
+		{"lines", CaptionValidationConfig{MaxLines: 1}, "а\nб", "", "Подпись содержит слишком много строк, максимум: 1."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator, err := NewCaptionValidator(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := validator.Validate(tt.text)
+			if tt.message != "" {
+				if err == nil || apperror.As(err).Message != tt.message {
+					t.Fatalf("Validate(%q) error = %v, want %q", tt.text, err, tt.message)
+				}
+				return
+			}
+
+			if err != nil || got != tt.want {
+				t.Errorf("Validate(%q) = %q, %v, want %q", tt.text, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"markoslav/pkg/apperror"
+	"sync"
+	"time"
+)
+
+type ChatMemberService interface {
+	IsChatAdmin(ctx context.Context, chatID int64, userID int64) (bool, error)
+}
+
+type chatMemberKey struct {
+	chatID int64
+	userID int64
+}
+
+type cachedChatMember struct {
+	isAdmin   bool
+	expiresAt time.Time
+}
+
+type chatMemberService struct {
+	api *tgbotapi.BotAPI
+
+	cacheTTL time.Duration
+	cacheMu  sync.RWMutex
+	cache    map[chatMemberKey]cachedChatMember
+}
+
+func NewChatMemberService(api *tgbotapi.BotAPI, cacheTTL time.Duration) ChatMemberService {
+	return &chatMemberService{
+		api:      api,
+		cacheTTL: cacheTTL,
+		cache:    make(map[chatMemberKey]cachedChatMember),
+	}
+}
+
+func (service *chatMemberService) IsChatAdmin(_ context.Context, chatID int64, userID int64) (bool, error) {
+	key := chatMemberKey{chatID: chatID, userID: userID}
+
+	service.cacheMu.RLock()
+	cached, ok := service.cache[key]
+	service.cacheMu.RUnlock()
+
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.isAdmin, nil
+	}
+
+	member, err := service.api.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: userID},
+	})
+	if err != nil {
+		return false, apperror.Internal.WithError(err)
+	}
+
+	isAdmin := member.IsCreator() || member.IsAdministrator()
+
+	service.cacheMu.Lock()
+	service.cache[key] = cachedChatMember{isAdmin: isAdmin, expiresAt: time.Now().Add(service.cacheTTL)}
+	service.cacheMu.Unlock()
+
+	return isAdmin, nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/storage"
+	"markoslav/pkg/apperror"
+	"time"
+)
+
+type ChatSettingsService interface {
+	// Get returns default settings for chats that have not changed anything.
+	Get(ctx context.Context, chatID int64) (model.ChatSettings, error)
+	Update(ctx context.Context, request dto.UpdateChatSettings) (model.ChatSettings, error)
+}
+
+type chatSettingsService struct {
+	storage storage.ChatSettingsStorage
+}
+
+func NewChatSettingsService(storage storage.ChatSettingsStorage) ChatSettingsService {
+	return &chatSettingsService{storage: storage}
+}
+
+func (service *chatSettingsService) Get(ctx context.Context, chatID int64) (model.ChatSettings, error) {
+	settings, err := service.storage.GetByChatID(ctx, chatID)
+	if err != nil {
+		if _, ok := apperror.Is(err, apperror.NotFound); ok {
+			return model.ChatSettings{ChatID: chatID}, nil
+		}
+
+		return model.ChatSettings{}, err
+	}
+
+	return settings, nil
+}
+
+func (service *chatSettingsService) Update(ctx context.Context, request dto.UpdateChatSettings) (model.ChatSettings, error) {
+	settings, err := service.Get(ctx, request.ChatID)
+	if err != nil {
+		return model.ChatSettings{}, err
+	}
+
+	if request.ImageFormat != nil {
+		if *request.ImageFormat != model.ImageFormatDefault && !request.ImageFormat.Valid() {
+			return model.ChatSettings{}, apperror.BadRequest.WithMessage("Неизвестный формат изображения.")
+		}
+
+		settings.ImageFormat = *request.ImageFormat
+	}
+
+	if request.TextStyle != nil {
+		if *request.TextStyle != model.TextStyleDefault && !request.TextStyle.Valid() {
+			return model.ChatSettings{}, apperror.BadRequest.WithMessage("Неизвестный стиль подписи.")
+		}
+
+		settings.TextStyle = *request.TextStyle
+	}
+
+	if request.CustomCaptionsDisabled != nil {
+		settings.CustomCaptionsDisabled = *request.CustomCaptionsDisabled
+	}
+
+	settings.UpdatedBy = request.UpdatedBy
+	settings.UpdatedAt = time.Now()
+
+	if err = service.storage.Save(ctx, settings); err != nil {
+		return model.ChatSettings{}, err
+	}
+
+	return settings, nil
+}
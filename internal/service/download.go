@@ -0,0 +1,158 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"markoslav/internal/dto"
+	"markoslav/pkg/apperror"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ImageContentTypes are the sniffed content types of images that can be decoded.
+var ImageContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+}
+
+type DownloadConfig struct {
+	Timeout    time.Duration
+	Retries    int
+	RetryDelay time.Duration
+	MaxBytes   int64
+}
+
+type DownloadService interface {
+	Download(ctx context.Context, request dto.Download) ([]byte, error)
+	DownloadImage(ctx context.Context, request dto.Download) (image.Image, error)
+}
+
+type downloadService struct {
+	client *http.Client
+	config DownloadConfig
+}
+
+func NewDownloadService(config DownloadConfig) DownloadService {
+	return &downloadService{
+		client: &http.Client{Timeout: config.Timeout},
+		config: config,
+	}
+}
+
+// errRetryable marks failures worth another attempt: network errors, 5xx and 429 responses.
+var errRetryable = errors.New("retryable")
+
+func (service *downloadService) Download(ctx context.Context, request dto.Download) ([]byte, error) {
+	maxBytes := service.config.MaxBytes
+	if request.MaxBytes > 0 {
+		maxBytes = request.MaxBytes
+	}
+
+	if maxBytes > 0 && int64(request.Size) > maxBytes {
+		return nil, apperror.BadRequest.WithMessage("Файл слишком большой.")
+	}
+
+	var err error
+	for attempt := 0; attempt <= service.config.Retries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(service.config.RetryDelay << (attempt - 1))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, apperror.Internal.WithError(ctx.Err())
+			case <-timer.C:
+			}
+		}
+
+		var data []byte
+		data, err = service.fetch(ctx, request.URL, maxBytes)
+		if err == nil {
+			return data, nil
+		}
+
+		if !errors.Is(err, errRetryable) {
+			return nil, err
+		}
+	}
+
+	return nil, apperror.Internal.WithError(err)
+}
+
+func (service *downloadService) DownloadImage(ctx context.Context, request dto.Download) (image.Image, error) {
+	data, err := service.Download(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ImageContentTypes[http.DetectContentType(data)] {
+		return nil, apperror.BadRequest.WithMessage("Неподдерживаемый формат изображения.")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, apperror.BadRequest.WithMessage("Не удалось прочитать изображение.").WithError(err)
+	}
+
+	return img, nil
+}
+
+func (service *downloadService) fetch(ctx context.Context, address string, maxBytes int64) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return nil, apperror.Internal.WithError(withoutURL(err))
+	}
+
+	response, err := service.client.Do(request)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, apperror.Internal.WithError(ctx.Err())
+		}
+
+		return nil, fmt.Errorf("%w: %s", errRetryable, withoutURL(err))
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError:
+		return nil, fmt.Errorf("%w: status code: %d", errRetryable, response.StatusCode)
+	case response.StatusCode != http.StatusOK:
+		return nil, apperror.Internal.WithError(fmt.Errorf("status code: %d", response.StatusCode))
+	}
+
+	if maxBytes > 0 && response.ContentLength > maxBytes {
+		return nil, apperror.BadRequest.WithMessage("Файл слишком большой.")
+	}
+
+	body := io.Reader(response.Body)
+	if maxBytes > 0 {
+		body = io.LimitReader(response.Body, maxBytes+1)
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errRetryable, withoutURL(err))
+	}
+
+	if maxBytes > 0 && int64(len(data)) > maxBytes {
+		return nil, apperror.BadRequest.WithMessage("Файл слишком большой.")
+	}
+
+	return data, nil
+}
+
+// withoutURL drops the request URL from the error text: Telegram file URLs contain the bot token.
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s: %w", urlErr.Op, urlErr.Err)
+	}
+
+	return err
+}
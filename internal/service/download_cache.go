@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"image"
+	"markoslav/internal/dto"
+	"markoslav/pkg/lru"
+)
+
+type cachedDownloadService struct {
+	next   DownloadService
+	images *lru.Cache[string, image.Image]
+}
+
+// NewCachedDownloadService keeps the last downloaded images in memory by dto.Download.Key, up to
+// about maxBytes of decoded pixels. Cached images are shared, so they must not be modified.
+func NewCachedDownloadService(next DownloadService, maxBytes int64) DownloadService {
+	return &cachedDownloadService{
+		next:   next,
+		images: lru.NewWeighted[string, image.Image](maxBytes, imageBytes),
+	}
+}
+
+// imageBytes approximates the memory of a decoded image as four bytes per pixel.
+func imageBytes(img image.Image) int64 {
+	bounds := img.Bounds()
+
+	return int64(bounds.Dx()) * int64(bounds.Dy()) * 4
+}
+
+func (service *cachedDownloadService) Download(ctx context.Context, request dto.Download) ([]byte, error) {
+	return service.next.Download(ctx, request)
+}
+
+func (service *cachedDownloadService) DownloadImage(ctx context.Context, request dto.Download) (image.Image, error) {
+	if request.Key == "" {
+		return service.next.DownloadImage(ctx, request)
+	}
+
+	if img, ok := service.images.Get(request.Key); ok {
+		return img, nil
+	}
+
+	img, err := service.next.DownloadImage(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	service.images.Add(request.Key, img)
+
+	return img, nil
+}
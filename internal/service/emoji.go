@@ -0,0 +1,261 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	zeroWidthJoiner  = 0x200D
+	variationEmoji   = 0xFE0F
+	combiningKeycap  = 0x20E3
+	regionalIndexMin = 0x1F1E6
+	regionalIndexMax = 0x1F1FF
+)
+
+// emojiPresentation lists BMP characters that are shown as emoji even without the U+FE0F selector.
+var emojiPresentation = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x231A, Hi: 0x231B, Stride: 1},
+		{Lo: 0x23E9, Hi: 0x23EC, Stride: 1},
+		{Lo: 0x23F0, Hi: 0x23F3, Stride: 3},
+		{Lo: 0x25FD, Hi: 0x25FE, Stride: 1},
+		{Lo: 0x2614, Hi: 0x2615, Stride: 1},
+		{Lo: 0x2648, Hi: 0x2653, Stride: 1},
+		{Lo: 0x267F, Hi: 0x2693, Stride: 20},
+		{Lo: 0x26A1, Hi: 0x26A1, Stride: 1},
+		{Lo: 0x26AA, Hi: 0x26AB, Stride: 1},
+		{Lo: 0x26BD, Hi: 0x26BE, Stride: 1},
+		{Lo: 0x26C4, Hi: 0x26C5, Stride: 1},
+		{Lo: 0x26CE, Hi: 0x26D4, Stride: 6},
+		{Lo: 0x26EA, Hi: 0x26EA, Stride: 1},
+		{Lo: 0x26F2, Hi: 0x26F3, Stride: 1},
+		{Lo: 0x26F5, Hi: 0x26FA, Stride: 5},
+		{Lo: 0x26FD, Hi: 0x26FD, Stride: 1},
+		{Lo: 0x2705, Hi: 0x2705, Stride: 1},
+		{Lo: 0x270A, Hi: 0x270B, Stride: 1},
+		{Lo: 0x2728, Hi: 0x2728, Stride: 1},
+		{Lo: 0x274C, Hi: 0x274E, Stride: 2},
+		{Lo: 0x2753, Hi: 0x2755, Stride: 1},
+		{Lo: 0x2757, Hi: 0x2757, Stride: 1},
+		{Lo: 0x2795, Hi: 0x2797, Stride: 1},
+		{Lo: 0x27B0, Hi: 0x27BF, Stride: 15},
+		{Lo: 0x2B1B, Hi: 0x2B1C, Stride: 1},
+		{Lo: 0x2B50, Hi: 0x2B55, Stride: 5},
+	},
+}
+
+// textRun is a piece of a caption line drawn either with the font or as an emoji image.
+type textRun struct {
+	text  string
+	emoji image.Image
+	width float64
+}
+
+// emojiSet loads emoji images named after their code points, as in Twemoji: "1f44d.png", "1f1f7-1f1fa.png".
+type emojiSet struct {
+	dir string
+
+	mu     sync.Mutex
+	images map[string]image.Image
+}
+
+func newEmojiSet(dir string) *emojiSet {
+	if dir != "" {
+		if _, err := os.Stat(dir); err != nil {
+			log.Printf("emoji images are not available, emoji are drawn with the font: %s", err)
+		}
+	}
+
+	return &emojiSet{dir: dir, images: make(map[string]image.Image)}
+}
+
+// split breaks text into runs of plain text and emoji. Emoji without an image stay in the text.
+func (set *emojiSet) split(text string) []textRun {
+	var (
+		runs  []textRun
+		plain strings.Builder
+	)
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		end := emojiEnd(runes, i)
+		if end == i {
+			plain.WriteRune(runes[i])
+			i++
+			continue
+		}
+
+		sequence := runes[i:end]
+		i = end
+
+		img := set.get(sequence)
+		if img == nil {
+			plain.WriteString(string(sequence))
+			continue
+		}
+
+		if plain.Len() > 0 {
+			runs = append(runs, textRun{text: plain.String()})
+			plain.Reset()
+		}
+		runs = append(runs, textRun{text: string(sequence), emoji: img})
+	}
+
+	if plain.Len() > 0 {
+		runs = append(runs, textRun{text: plain.String()})
+	}
+
+	return runs
+}
+
+// get returns the image of an emoji sequence or nil when there is none.
+func (set *emojiSet) get(sequence []rune) image.Image {
+	if set.dir == "" {
+		return nil
+	}
+
+	key := string(sequence)
+
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	if img, ok := set.images[key]; ok {
+		return img
+	}
+
+	var img image.Image
+	for _, name := range emojiFileNames(sequence) {
+		loaded, err := loadPNG(filepath.Join(set.dir, name))
+		if err == nil {
+			img = loaded
+			break
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("load emoji %s: %s", name, err)
+		}
+	}
+
+	// Misses are not cached: user text can contain any number of unknown sequences.
+	if img != nil {
+		set.images[key] = img
+	}
+
+	return img
+}
+
+func loadPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return png.Decode(file)
+}
+
+// emojiFileNames returns candidate file names of an emoji sequence. Twemoji drops U+FE0F
+// unless the sequence has a zero width joiner, other sets keep it.
+func emojiFileNames(sequence []rune) []string {
+	var full, stripped []string
+	hasJoiner := false
+
+	for _, r := range sequence {
+		code := fmt.Sprintf("%x", r)
+		full = append(full, code)
+
+		if r != variationEmoji {
+			stripped = append(stripped, code)
+		}
+		if r == zeroWidthJoiner {
+			hasJoiner = true
+		}
+	}
+
+	names := []string{strings.Join(stripped, "-") + ".png", strings.Join(full, "-") + ".png"}
+	if hasJoiner {
+		names[0], names[1] = names[1], names[0]
+	}
+	if names[0] == names[1] {
+		names = names[:1]
+	}
+
+	return names
+}
+
+// emojiEnd returns the end of the emoji sequence starting at i, or i if there is none.
+func emojiEnd(runes []rune, i int) int {
+	r := runes[i]
+	next := func(j int) rune {
+		if j < len(runes) {
+			return runes[j]
+		}
+		return 0
+	}
+
+	switch {
+	case isKeycapBase(r):
+		// 1️⃣ is a digit, an optional U+FE0F and the combining keycap.
+		j := i + 1
+		if next(j) == variationEmoji {
+			j++
+		}
+		if next(j) != combiningKeycap {
+			return i
+		}
+		return j + 1
+	case isRegionalIndicator(r):
+		// Flags are pairs of regional indicators.
+		if isRegionalIndicator(next(i + 1)) {
+			return i + 2
+		}
+		return i + 1
+	case !isEmojiBase(r) && next(i+1) != variationEmoji:
+		return i
+	}
+
+	j := i + 1
+	for j < len(runes) {
+		switch c := runes[j]; {
+		case c == variationEmoji, c == combiningKeycap, isSkinTone(c), isTag(c):
+			j++
+		case c == zeroWidthJoiner && j+1 < len(runes):
+			j += 2
+		default:
+			return j
+		}
+	}
+
+	return j
+}
+
+func isEmojiBase(r rune) bool {
+	return (r >= 0x1F000 && r <= 0x1FAFF && !isSkinTone(r)) || unicode.Is(emojiPresentation, r)
+}
+
+func isKeycapBase(r rune) bool {
+	return (r >= '0' && r <= '9') || r == '#' || r == '*'
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= regionalIndexMin && r <= regionalIndexMax
+}
+
+func isSkinTone(r rune) bool {
+	return r >= 0x1F3FB && r <= 0x1F3FF
+}
+
+// isTag matches tag characters used by subdivision flags such as 🏴󠁧󠁢󠁥󠁮󠁧󠁿.
+func isTag(r rune) bool {
+	return r >= 0xE0020 && r <= 0xE007F
+}
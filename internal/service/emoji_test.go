@@ -0,0 +1,38 @@
+package service
+
+import "testing"
+
+func TestEmojiEnd(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		start int
+		want  int
+	}{
+		{"letter", "a", 0, 0},
+		{"emoji", "👍", 0, 1},
+		{"skin tone", "👍🏽", 0, 2},
+		{"variation selector", "❤\ufe0f", 0, 2},
+		{"text presentation", "❤", 0, 0},
+		{"emoji presentation", "⌚", 0, 1},
+		{"inside text", "a👍b", 1, 2},
+		{"zwj family", "👨\u200d👩\u200d👧", 0, 5},
+		{"zwj rainbow flag", "🏳\ufe0f\u200d🌈", 0, 4},
+		{"trailing zwj", "👍\u200d", 0, 1},
+		{"keycap", "1\ufe0f\u20e3", 0, 3},
+		{"keycap without selector", "1\u20e3", 0, 2},
+		{"digit", "1", 0, 0},
+		{"digit with selector", "1\ufe0f", 0, 0},
+		{"flag", "🇷🇺", 0, 2},
+		{"lone regional indicator", "🇷", 0, 1},
+		{"tag sequence", "🏴\U000e0067\U000e0062\U000e0065\U000e006e\U000e0067\U000e007f", 0, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := emojiEnd([]rune(tt.text), tt.start); got != tt.want {
+				t.Errorf("emojiEnd(%q, %d) = %d, want %d", tt.text, tt.start, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/pkg/apperror"
+)
+
+type EncoderConfig struct {
+	Format      model.ImageFormat
+	JPEGQuality int
+}
+
+type EncoderService interface {
+	// Encode encodes the image in the format, or the configured one for model.ImageFormatDefault.
+	// Images with transparency are always encoded as PNG.
+	Encode(ctx context.Context, img image.Image, format model.ImageFormat) (dto.EncodedImage, error)
+}
+
+type encoderService struct {
+	config EncoderConfig
+}
+
+func NewEncoderService(config EncoderConfig) EncoderService {
+	return &encoderService{config: config}
+}
+
+func (service *encoderService) Encode(_ context.Context, img image.Image, format model.ImageFormat) (dto.EncodedImage, error) {
+	if format == model.ImageFormatDefault {
+		format = service.config.Format
+	}
+
+	if !isOpaque(img) {
+		format = model.ImageFormatPNG
+	}
+
+	buffer := new(bytes.Buffer)
+
+	var err error
+	switch format {
+	case model.ImageFormatJPEG:
+		err = jpeg.Encode(buffer, img, &jpeg.Options{Quality: service.config.JPEGQuality})
+	case model.ImageFormatPNG:
+		err = png.Encode(buffer, img)
+	default:
+		return dto.EncodedImage{}, apperror.BadRequest.WithMessage("Неизвестный формат изображения.")
+	}
+	if err != nil {
+		return dto.EncodedImage{}, apperror.Internal.WithError(err)
+	}
+
+	return dto.EncodedImage{Data: buffer.Bytes(), Format: format}, nil
+}
+
+func isOpaque(img image.Image) bool {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok {
+		return opaque.Opaque()
+	}
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"markoslav/internal/storage"
+	"markoslav/pkg/postgres"
+	"sync"
+	"time"
+)
+
+type HealthCheck func(ctx context.Context) error
+
+type HealthService interface {
+	// Ready runs all checks concurrently and returns their results by name, nil meaning healthy.
+	Ready(ctx context.Context) map[string]error
+}
+
+type healthService struct {
+	checks  map[string]HealthCheck
+	timeout time.Duration
+}
+
+func NewHealthService(checks map[string]HealthCheck, timeout time.Duration) HealthService {
+	return &healthService{checks: checks, timeout: timeout}
+}
+
+func (service *healthService) Ready(ctx context.Context) map[string]error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]error, len(service.checks))
+	)
+
+	for name, check := range service.checks {
+		wg.Add(1)
+
+		go func(name string, check HealthCheck) {
+			defer wg.Done()
+
+			err := withContext(ctx, check)
+
+			mu.Lock()
+			results[name] = err
+			mu.Unlock()
+		}(name, check)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// withContext stops waiting for checks that ignore context cancellation.
+func withContext(ctx context.Context, check HealthCheck) error {
+	done := make(chan error, 1)
+
+	go func() {
+		done <- check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func PostgresHealthCheck(client postgres.Client) HealthCheck {
+	return client.Ping
+}
+
+func TelegramHealthCheck(api *tgbotapi.BotAPI) HealthCheck {
+	return func(_ context.Context) error {
+		_, err := api.GetMe()
+
+		return err
+	}
+}
+
+func MigrationsHealthCheck(storage storage.MigrationStorage, expectedVersion int64) HealthCheck {
+	return func(ctx context.Context) error {
+		version, err := storage.Version(ctx)
+		if err != nil {
+			return err
+		}
+
+		if version < expectedVersion {
+			return fmt.Errorf("database version %d is behind %d", version, expectedVersion)
+		}
+
+		return nil
+	}
+}
@@ -3,44 +3,264 @@ package service
 import (
 	"context"
 	"github.com/fogleman/gg"
+	"golang.org/x/image/draw"
 	"image"
+	"markoslav/internal/dto"
 	"markoslav/internal/model"
+	"strings"
 )
 
 type ImageService interface {
-	Draw(ctx context.Context, caption model.Caption, img image.Image) (image.Image, error)
+	Draw(ctx context.Context, caption model.Caption, img image.Image, options dto.DrawOptions) (image.Image, error)
+}
+
+// lineSpacing is the distance between caption lines relative to the font height.
+const lineSpacing = 1.3
+
+type ImageConfig struct {
+	FontPath string
+	// EmojiDir holds colour emoji PNGs drawn in place of characters the font lacks, empty disables.
+	EmojiDir string
+	// MaxDimension is the largest allowed side of an image; larger ones are downscaled before drawing, 0 disables.
+	MaxDimension    int
+	Placement       Placement
+	TextStyle       model.TextStyle
+	BackdropOpacity float64
 }
 
 type imageService struct {
-	fontPath string
+	config ImageConfig
+	emoji  *emojiSet
 }
 
-func NewImageService(fontPath string) ImageService {
-	return &imageService{fontPath: fontPath}
+func NewImageService(config ImageConfig) ImageService {
+	return &imageService{config: config, emoji: newEmojiSet(config.EmojiDir)}
 }
 
-func (service *imageService) Draw(_ context.Context, caption model.Caption, img image.Image) (image.Image, error) {
-	c := gg.NewContextForImage(img)
+// textLine is a wrapped caption line.
+type textLine struct {
+	runs  []textRun
+	width float64
+}
 
-	width := float64(c.Width())
-	height := float64(c.Height())
+// textBlock is a wrapped piece of text drawn as a whole.
+type textBlock struct {
+	lines  []textLine
+	width  float64
+	height float64
+}
+
+func (service *imageService) Draw(
+	_ context.Context, caption model.Caption, img image.Image, options dto.DrawOptions,
+) (image.Image, error) {
+	c := gg.NewContextForImage(downscale(img, service.config.MaxDimension))
+	rgba := c.Image().(*image.RGBA)
+
+	width, height := float64(c.Width()), float64(c.Height())
 	padding := 20.0
 
-	if err := c.LoadFontFace(service.fontPath, width*0.08); err != nil {
+	if err := c.LoadFontFace(service.config.FontPath, width*0.08); err != nil {
 		return nil, err
 	}
 
-	c.FontHeight()
+	style := options.TextStyle
+	if style == model.TextStyleDefault {
+		style = service.config.TextStyle
+	}
+
+	text := caption.Text
+
+	// Two-part captions go to the top and the bottom, regardless of the placement. When the parts
+	// would overlap, e.g. on a wide photo, they are drawn as one block of two paragraphs instead.
+	if caption.TopText != "" {
+		top := service.layout(c, caption.TopText, width)
+		bottom := service.layout(c, caption.BottomText, width)
+
+		if top.height+bottom.height+3*padding <= height {
+			service.drawBlock(c, rgba, top, padding, padding, style)
+			service.drawBlock(c, rgba, bottom, height-padding-bottom.height, padding, style)
 
-	c.SetRGB(0, 0, 0)
-	c.DrawStringWrapped(caption.Text,
-		width/2, height-padding, 0.5, 1, width, 1.3, gg.AlignCenter,
-	)
+			return c.Image(), nil
+		}
+
+		text = caption.TopText + "\n" + caption.BottomText
+	}
 
-	c.SetRGB(1, 1, 1)
-	c.DrawStringWrapped(caption.Text,
-		width/2, height-padding-padding/6, 0.5, 1, width, 1.3, gg.AlignCenter,
-	)
+	block := service.layout(c, text, width)
+	service.drawBlock(c, rgba, block, service.config.Placement.place(rgba, block.height, padding), padding, style)
 
 	return c.Image(), nil
 }
+
+func (service *imageService) layout(c *gg.Context, text string, width float64) textBlock {
+	lines := service.wrap(c, text, width)
+
+	block := textBlock{
+		lines:  lines,
+		height: float64(len(lines))*c.FontHeight()*lineSpacing - (lineSpacing-1)*c.FontHeight(),
+	}
+	for _, line := range lines {
+		if line.width > block.width {
+			block.width = line.width
+		}
+	}
+
+	return block
+}
+
+// drawBlock draws the block centered horizontally at the given top in the given style.
+func (service *imageService) drawBlock(
+	c *gg.Context, rgba *image.RGBA, block textBlock, top float64, padding float64, style model.TextStyle,
+) {
+	width := float64(c.Width())
+	left := (width - block.width) / 2
+
+	text, shadow, hasShadow := 1.0, 0.0, true
+	switch style {
+	case model.TextStyleAuto:
+		box := image.Rect(int(left), int(top), int(left+block.width), int(top+block.height))
+
+		// Dark text with a light shadow reads better on bright backgrounds.
+		if analyzeRegion(rgba, box).luminance > 0.5 {
+			text, shadow = 0, 1
+		}
+	case model.TextStyleBackdrop:
+		margin := c.FontHeight() / 3
+
+		c.SetRGBA(0, 0, 0, service.config.BackdropOpacity)
+		c.DrawRoundedRectangle(left-margin, top-margin, block.width+2*margin, block.height+2*margin, margin)
+		c.Fill()
+
+		hasShadow = false
+	}
+
+	if hasShadow {
+		c.SetRGB(shadow, shadow, shadow)
+		drawLines(c, block.lines, width/2, top+padding/6, false)
+	}
+
+	c.SetRGB(text, text, text)
+	drawLines(c, block.lines, width/2, top, true)
+}
+
+// wrap splits text into lines no wider than width, measuring emoji as squares of the font height.
+func (service *imageService) wrap(c *gg.Context, text string, width float64) []textLine {
+	space, _ := c.MeasureString(" ")
+
+	var lines []textLine
+	for _, paragraph := range strings.Split(text, "\n") {
+		var line textLine
+
+		for i, word := range strings.Fields(paragraph) {
+			runs := service.emoji.split(word)
+
+			var wordWidth float64
+			for j := range runs {
+				runs[j].width = runWidth(c, runs[j])
+				wordWidth += runs[j].width
+			}
+
+			if i > 0 && line.width+space+wordWidth > width {
+				lines = append(lines, line)
+				line = textLine{}
+			}
+
+			if len(line.runs) > 0 {
+				line.runs = appendRun(line.runs, textRun{text: " ", width: space})
+				line.width += space
+			}
+			for _, run := range runs {
+				line.runs = appendRun(line.runs, run)
+			}
+			line.width += wordWidth
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// appendRun merges neighbouring text runs so that they are drawn in one call.
+func appendRun(runs []textRun, run textRun) []textRun {
+	if last := len(runs) - 1; last >= 0 && runs[last].emoji == nil && run.emoji == nil {
+		runs[last].text += run.text
+		runs[last].width += run.width
+		return runs
+	}
+
+	return append(runs, run)
+}
+
+func runWidth(c *gg.Context, run textRun) float64 {
+	if run.emoji != nil {
+		return c.FontHeight()
+	}
+
+	width, _ := c.MeasureString(run.text)
+	return width
+}
+
+// drawLines draws centered lines starting at top. Emoji are skipped unless withEmoji is set,
+// so that shadows only outline the text.
+func drawLines(c *gg.Context, lines []textLine, centerX, top float64, withEmoji bool) {
+	size := c.FontHeight()
+
+	for i, line := range lines {
+		x := centerX - line.width/2
+		baseline := top + float64(i)*size*lineSpacing + size
+
+		for _, run := range line.runs {
+			switch {
+			case run.emoji == nil:
+				c.DrawString(run.text, x, baseline)
+			case withEmoji:
+				c.DrawImage(scaleSquare(run.emoji, int(size)), int(x), int(baseline-size))
+			}
+
+			x += run.width
+		}
+	}
+}
+
+// scaleSquare resizes an emoji image to size×size pixels.
+func scaleSquare(img image.Image, size int) image.Image {
+	if size < 1 {
+		size = 1
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, img.Bounds(), draw.Over, nil)
+
+	return scaled
+}
+
+// downscale shrinks the image so that neither side exceeds maxDimension, keeping the aspect ratio.
+func downscale(img image.Image, maxDimension int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if maxDimension <= 0 || (width <= maxDimension && height <= maxDimension) {
+		return img
+	}
+
+	if width >= height {
+		height = height * maxDimension / width
+		width = maxDimension
+	} else {
+		width = width * maxDimension / height
+		height = maxDimension
+	}
+
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, bounds, draw.Src, nil)
+
+	return scaled
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/storage"
+	"markoslav/pkg/filter"
+	"time"
+)
+
+type ModerationEventService interface {
+	Create(ctx context.Context, request dto.CreateModerationEvent) (model.ModerationEvent, error)
+
+	Select(ctx context.Context, count int, offset int, options filter.Options) ([]model.ModerationEvent, error)
+}
+
+type moderationEventService struct {
+	storage storage.ModerationEventStorage
+}
+
+func NewModerationEventService(storage storage.ModerationEventStorage) ModerationEventService {
+	return &moderationEventService{storage: storage}
+}
+
+func (service *moderationEventService) Create(ctx context.Context, request dto.CreateModerationEvent) (model.ModerationEvent, error) {
+	event := model.ModerationEvent{
+		ID:          uuid.New(),
+		CaptionID:   request.CaptionID,
+		CaptionText: request.CaptionText,
+		ModeratorID: request.ModeratorID,
+		Action:      request.Action,
+		Reason:      request.Reason,
+		CreatedAt:   time.Now(),
+	}
+	err := service.storage.Create(ctx, event)
+	if err != nil {
+		return model.ModerationEvent{}, err
+	}
+
+	return event, nil
+}
+
+func (service *moderationEventService) Select(ctx context.Context, count int, offset int, options filter.Options) ([]model.ModerationEvent, error) {
+	events, err := service.storage.Select(ctx, count, offset, options)
+	if err != nil {
+		return []model.ModerationEvent{}, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"image"
+	"math"
+)
+
+type Placement string
+
+const (
+	// PlacementBottom always puts captions at the bottom of the image.
+	PlacementBottom Placement = "bottom"
+	// PlacementSmart puts captions into the calmest of the bottom, top and middle bands.
+	PlacementSmart Placement = "smart"
+)
+
+func (placement Placement) Valid() bool {
+	return placement == PlacementBottom || placement == PlacementSmart
+}
+
+// bottomBias makes the bottom band win unless another band is clearly calmer.
+const bottomBias = 0.8
+
+// analysisWidth is the approximate number of samples per row used to analyze a region.
+const analysisWidth = 160
+
+// regionStats describes the luminance of an image region, all values in [0, 1].
+type regionStats struct {
+	luminance float64
+	deviation float64
+	edges     float64
+}
+
+// busyness grows with texture and contrast in the region, where text is hard to read.
+func (stats regionStats) busyness() float64 {
+	return stats.edges + stats.deviation
+}
+
+// place returns the top of a caption block of the given height.
+func (placement Placement) place(img *image.RGBA, blockHeight float64, padding float64) float64 {
+	bounds := img.Bounds()
+	height := float64(bounds.Dy())
+
+	bottom := height - padding - blockHeight
+	candidates := []float64{bottom}
+	if placement == PlacementSmart {
+		candidates = append(candidates, padding, (height-blockHeight)/2)
+	}
+
+	var (
+		bestTop   float64
+		bestScore = math.Inf(1)
+	)
+	for i, top := range candidates {
+		rect := image.Rect(
+			bounds.Min.X, bounds.Min.Y+int(math.Max(0, top)),
+			bounds.Max.X, bounds.Min.Y+int(math.Min(height, top+blockHeight)),
+		)
+
+		score := analyzeRegion(img, rect).busyness()
+		if i == 0 {
+			score *= bottomBias
+		}
+
+		if score < bestScore {
+			bestTop, bestScore = top, score
+		}
+	}
+
+	return bestTop
+}
+
+// analyzeRegion samples the region on a coarse grid and measures its mean luminance,
+// luminance standard deviation and mean luminance difference between neighbouring samples.
+func analyzeRegion(img *image.RGBA, rect image.Rectangle) regionStats {
+	rect = rect.Intersect(img.Bounds())
+	if rect.Empty() {
+		return regionStats{}
+	}
+
+	step := rect.Dx() / analysisWidth
+	if step < 1 {
+		step = 1
+	}
+
+	var sum, sumSquares, edges float64
+	var samples, edgeSamples int
+	for y := rect.Min.Y; y < rect.Max.Y; y += step {
+		for x := rect.Min.X; x < rect.Max.X; x += step {
+			l := luminance(img, x, y)
+
+			sum += l
+			sumSquares += l * l
+			samples++
+
+			if x+step < rect.Max.X {
+				edges += math.Abs(luminance(img, x+step, y) - l)
+				edgeSamples++
+			}
+			if y+step < rect.Max.Y {
+				edges += math.Abs(luminance(img, x, y+step) - l)
+				edgeSamples++
+			}
+		}
+	}
+
+	mean := sum / float64(samples)
+	stats := regionStats{
+		luminance: mean,
+		deviation: math.Sqrt(math.Max(0, sumSquares/float64(samples)-mean*mean)),
+	}
+	if edgeSamples > 0 {
+		stats.edges = edges / float64(edgeSamples)
+	}
+
+	return stats
+}
+
+// luminance returns the relative luminance of the pixel in [0, 1].
+func luminance(img *image.RGBA, x, y int) float64 {
+	i := img.PixOffset(x, y)
+	r, g, b := img.Pix[i], img.Pix[i+1], img.Pix[i+2]
+
+	return (0.2126*float64(r) + 0.7152*float64(g) + 0.0722*float64(b)) / 255
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/storage"
+	"time"
+)
+
+type RenderedImageService interface {
+	Save(ctx context.Context, request dto.SaveRenderedImage) error
+
+	// GetFileID returns apperror.NotFound if the image was not rendered yet.
+	GetFileID(ctx context.Context, key dto.RenderedImageKey) (string, error)
+}
+
+type renderedImageService struct {
+	storage storage.RenderedImageStorage
+	// fingerprint identifies the global rendering settings, so that changing them makes old renders stale.
+	fingerprint string
+}
+
+func NewRenderedImageService(storage storage.RenderedImageStorage, fingerprint string) RenderedImageService {
+	return &renderedImageService{storage: storage, fingerprint: fingerprint}
+}
+
+func (service *renderedImageService) Save(ctx context.Context, request dto.SaveRenderedImage) error {
+	return service.storage.Save(ctx, model.RenderedImage{
+		SourceFileUniqueID: request.SourceFileUniqueID,
+		CaptionID:          request.CaptionID,
+		Style:              service.style(request.RenderedImageKey),
+		FileID:             request.FileID,
+		CreatedAt:          time.Now(),
+	})
+}
+
+func (service *renderedImageService) GetFileID(ctx context.Context, key dto.RenderedImageKey) (string, error) {
+	key.Style = service.style(key)
+
+	renderedImage, err := service.storage.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	return renderedImage.FileID, nil
+}
+
+func (service *renderedImageService) style(key dto.RenderedImageKey) string {
+	if service.fingerprint == "" {
+		return key.Style
+	}
+
+	return key.Style + "@" + service.fingerprint
+}
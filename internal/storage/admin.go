@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"markoslav/internal/model"
+	"markoslav/pkg/apperror"
+	"markoslav/pkg/postgres"
+)
+
+type AdminStorage interface {
+	Save(ctx context.Context, admin model.Admin) error
+
+	GetByUserID(ctx context.Context, userID int64) (model.Admin, error)
+
+	Select(ctx context.Context) ([]model.Admin, error)
+
+	Delete(ctx context.Context, userID int64) (bool, error)
+}
+
+type adminStorage struct {
+	client postgres.Client
+}
+
+func NewAdminStorage(client postgres.Client) AdminStorage {
+	return &adminStorage{client: client}
+}
+
+func (storage *adminStorage) Save(ctx context.Context, admin model.Admin) error {
+	builder := squirrel.Insert("bot_admin").
+		Columns("user_id", "role", "added_by", "created_at").
+		Values(admin.UserID, admin.Role, admin.AddedBy, admin.CreatedAt).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET role = EXCLUDED.role, added_by = EXCLUDED.added_by").
+		PlaceholderFormat(squirrel.Dollar)
+
+	q, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	_, err = storage.client.Exec(ctx, q, args...)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *adminStorage) GetByUserID(ctx context.Context, userID int64) (model.Admin, error) {
+	builder := squirrel.Select("user_id", "role", "added_by", "created_at").
+		From("bot_admin").
+		Where(squirrel.Eq{"user_id": userID}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	q, args, err := builder.ToSql()
+	if err != nil {
+		return model.Admin{}, apperror.Internal.WithError(err)
+	}
+
+	var admin model.Admin
+	err = storage.client.Get(ctx, &admin, q, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Admin{}, apperror.NotFound.WithError(err)
+		}
+
+		return model.Admin{}, apperror.Internal.WithError(err)
+	}
+
+	return admin, nil
+}
+
+func (storage *adminStorage) Select(ctx context.Context) ([]model.Admin, error) {
+	builder := squirrel.Select("user_id", "role", "added_by", "created_at").
+		From("bot_admin").
+		OrderBy("created_at").
+		PlaceholderFormat(squirrel.Dollar)
+
+	q, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.Internal.WithError(err)
+	}
+
+	var admins []model.Admin
+	err = storage.client.Select(ctx, &admins, q, args...)
+	if err != nil {
+		return nil, apperror.Internal.WithError(err)
+	}
+
+	return admins, nil
+}
+
+func (storage *adminStorage) Delete(ctx context.Context, userID int64) (bool, error) {
+	builder := squirrel.Delete("bot_admin").
+		Where(squirrel.Eq{"user_id": userID}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	q, args, err := builder.ToSql()
+	if err != nil {
+		return false, apperror.Internal.WithError(err)
+	}
+
+	tag, err := storage.client.Exec(ctx, q, args...)
+	if err != nil {
+		return false, apperror.Internal.WithError(err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
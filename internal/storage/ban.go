@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"github.com/Masterminds/squirrel"
+	"markoslav/internal/model"
+	"markoslav/pkg/apperror"
+	"markoslav/pkg/postgres"
+)
+
+type BanStorage interface {
+	Save(ctx context.Context, ban model.Ban) error
+
+	ExistsByUserID(ctx context.Context, userID int64) (bool, error)
+
+	Delete(ctx context.Context, userID int64) (bool, error)
+}
+
+type banStorage struct {
+	client postgres.Client
+}
+
+func NewBanStorage(client postgres.Client) BanStorage {
+	return &banStorage{client: client}
+}
+
+func (storage *banStorage) Save(ctx context.Context, ban model.Ban) error {
+	builder := squirrel.Insert("user_ban").
+		Columns("user_id", "reason", "banned_by", "created_at").
+		Values(ban.UserID, ban.Reason, ban.BannedBy, ban.CreatedAt).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET reason = EXCLUDED.reason, banned_by = EXCLUDED.banned_by").
+		PlaceholderFormat(squirrel.Dollar)
+
+	q, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	_, err = storage.client.Exec(ctx, q, args...)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *banStorage) ExistsByUserID(ctx context.Context, userID int64) (bool, error) {
+	q := `SELECT EXISTS (SELECT 1 FROM user_ban WHERE user_id = $1)`
+
+	var exists bool
+	err := storage.client.Get(ctx, &exists, q, userID)
+	if err != nil {
+		return false, apperror.Internal.WithError(err)
+	}
+
+	return exists, nil
+}
+
+func (storage *banStorage) Delete(ctx context.Context, userID int64) (bool, error) {
+	builder := squirrel.Delete("user_ban").
+		Where(squirrel.Eq{"user_id": userID}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	q, args, err := builder.ToSql()
+	if err != nil {
+		return false, apperror.Internal.WithError(err)
+	}
+
+	tag, err := storage.client.Exec(ctx, q, args...)
+	if err != nil {
+		return false, apperror.Internal.WithError(err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
@@ -10,23 +10,28 @@ import (
 	"markoslav/pkg/apperror"
 	"markoslav/pkg/filter"
 	"markoslav/pkg/postgres"
+	"strconv"
 )
 
 type CaptionStorage interface {
 	Create(ctx context.Context, caption model.Caption) error
+	CreateMany(ctx context.Context, captions []model.Caption) (int, error)
 
 	GetByID(ctx context.Context, captionID uuid.UUID) (model.Caption, error)
 	GetRandom(ctx context.Context) (model.Caption, error)
 
-	ExistsByText(ctx context.Context, text string) (bool, error)
+	ExistsByNormalizedText(ctx context.Context, normalizedText string) (bool, error)
 
 	Select(ctx context.Context, count int, offset int, options filter.Options) ([]model.Caption, error)
+	SelectSimilar(ctx context.Context, caption model.Caption, threshold float64, count int) ([]model.Caption, error)
 
 	Update(ctx context.Context, caption model.Caption) error
 
 	Delete(ctx context.Context, captionID uuid.UUID) error
 }
 
+const createManyBatchSize = 500
+
 type captionStorage struct {
 	client postgres.Client
 }
@@ -37,8 +42,11 @@ func NewCaptionStorage(client postgres.Client) CaptionStorage {
 
 func (storage *captionStorage) Create(ctx context.Context, caption model.Caption) error {
 	builder := squirrel.Insert("caption").
-		Columns("id", "text", "author_id", "approved", "created_at").
-		Values(caption.ID, caption.Text, caption.AuthorID, caption.Approved, caption.CreatedAt).
+		Columns("id", "text", "top_text", "bottom_text", "normalized_text", "author_id", "approved", "created_at").
+		Values(
+			caption.ID, caption.Text, caption.TopText, caption.BottomText, caption.NormalizedText,
+			caption.AuthorID, caption.Approved, caption.CreatedAt,
+		).
 		PlaceholderFormat(squirrel.Dollar)
 
 	q, args, err := builder.ToSql()
@@ -48,14 +56,68 @@ func (storage *captionStorage) Create(ctx context.Context, caption model.Caption
 
 	_, err = storage.client.Exec(ctx, q, args...)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return apperror.AlreadyExists.WithError(err)
+		}
+
 		return apperror.Internal.WithError(err)
 	}
 
 	return nil
 }
 
+// CreateMany inserts captions in batches within a single transaction, silently skipping duplicates.
+// It returns the number of actually inserted captions.
+func (storage *captionStorage) CreateMany(ctx context.Context, captions []model.Caption) (int, error) {
+	tx, err := storage.client.Begin(ctx)
+	if err != nil {
+		return 0, apperror.Internal.WithError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	inserted := 0
+	for start := 0; start < len(captions); start += createManyBatchSize {
+		end := start + createManyBatchSize
+		if end > len(captions) {
+			end = len(captions)
+		}
+
+		builder := squirrel.Insert("caption").
+			Columns("id", "text", "top_text", "bottom_text", "normalized_text", "author_id", "approved", "created_at").
+			Suffix("ON CONFLICT DO NOTHING").
+			PlaceholderFormat(squirrel.Dollar)
+
+		for _, caption := range captions[start:end] {
+			builder = builder.Values(
+				caption.ID, caption.Text, caption.TopText, caption.BottomText, caption.NormalizedText,
+				caption.AuthorID, caption.Approved, caption.CreatedAt,
+			)
+		}
+
+		q, args, err := builder.ToSql()
+		if err != nil {
+			return 0, apperror.Internal.WithError(err)
+		}
+
+		tag, err := tx.Exec(ctx, q, args...)
+		if err != nil {
+			return 0, apperror.Internal.WithError(err)
+		}
+
+		inserted += int(tag.RowsAffected())
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, apperror.Internal.WithError(err)
+	}
+
+	return inserted, nil
+}
+
 func (storage *captionStorage) GetByID(ctx context.Context, captionID uuid.UUID) (model.Caption, error) {
-	builder := squirrel.Select("id", "text", "author_id", "approved", "created_at").
+	builder := squirrel.Select(
+		"id", "text", "top_text", "bottom_text", "normalized_text", "author_id", "approved", "created_at",
+	).
 		From("caption").
 		Where(squirrel.Eq{"id": captionID}).
 		PlaceholderFormat(squirrel.Dollar)
@@ -79,7 +141,9 @@ func (storage *captionStorage) GetByID(ctx context.Context, captionID uuid.UUID)
 }
 
 func (storage *captionStorage) GetRandom(ctx context.Context) (model.Caption, error) {
-	builder := squirrel.Select("id", "text", "author_id", "approved", "created_at").
+	builder := squirrel.Select(
+		"id", "text", "top_text", "bottom_text", "normalized_text", "author_id", "approved", "created_at",
+	).
 		From("caption").
 		OrderBy("random()").
 		Limit(1).
@@ -103,11 +167,11 @@ func (storage *captionStorage) GetRandom(ctx context.Context) (model.Caption, er
 	return caption, nil
 }
 
-func (storage *captionStorage) ExistsByText(ctx context.Context, text string) (bool, error) {
-	q := `SELECT EXISTS (SELECT 1 FROM caption WHERE text = $1)`
+func (storage *captionStorage) ExistsByNormalizedText(ctx context.Context, normalizedText string) (bool, error) {
+	q := `SELECT EXISTS (SELECT 1 FROM caption WHERE normalized_text = $1)`
 
 	var exists bool
-	err := storage.client.Get(ctx, &exists, q, text)
+	err := storage.client.Get(ctx, &exists, q, normalizedText)
 	if err != nil {
 		return false, apperror.Internal.WithError(err)
 	}
@@ -116,8 +180,11 @@ func (storage *captionStorage) ExistsByText(ctx context.Context, text string) (b
 }
 
 func (storage *captionStorage) Select(ctx context.Context, count int, offset int, options filter.Options) ([]model.Caption, error) {
-	builder := squirrel.Select("id", "text", "author_id", "approved", "created_at").
+	builder := squirrel.Select(
+		"id", "text", "top_text", "bottom_text", "normalized_text", "author_id", "approved", "created_at",
+	).
 		From("caption").
+		OrderBy("created_at", "id").
 		Limit(uint64(count)).
 		Offset(uint64(offset)).
 		PlaceholderFormat(squirrel.Dollar)
@@ -149,9 +216,50 @@ func (storage *captionStorage) Select(ctx context.Context, count int, offset int
 	return captions, nil
 }
 
+// SelectSimilar filters with the % operator, which unlike a similarity() comparison can use the trigram
+// index. Its threshold is set for the transaction only, as connections are shared through the pool.
+func (storage *captionStorage) SelectSimilar(ctx context.Context, caption model.Caption, threshold float64, count int) ([]model.Caption, error) {
+	tx, err := storage.client.Begin(ctx)
+	if err != nil {
+		return nil, apperror.Internal.WithError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `SELECT set_config('pg_trgm.similarity_threshold', $1, true)`, strconv.FormatFloat(threshold, 'f', -1, 64))
+	if err != nil {
+		return nil, apperror.Internal.WithError(err)
+	}
+
+	q := `
+		SELECT id, text, top_text, bottom_text, normalized_text, author_id, approved, created_at
+		FROM caption
+		WHERE id <> $1 AND normalized_text % $2
+		ORDER BY similarity(normalized_text, $2) DESC
+		LIMIT $3`
+
+	rows, err := tx.Query(ctx, q, caption.ID, caption.NormalizedText, count)
+	if err != nil {
+		return nil, apperror.Internal.WithError(err)
+	}
+
+	captions, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Caption])
+	if err != nil {
+		return nil, apperror.Internal.WithError(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, apperror.Internal.WithError(err)
+	}
+
+	return captions, nil
+}
+
 func (storage *captionStorage) Update(ctx context.Context, caption model.Caption) error {
 	builder := squirrel.Update("caption").
 		Set("text", caption.Text).
+		Set("top_text", caption.TopText).
+		Set("bottom_text", caption.BottomText).
+		Set("normalized_text", caption.NormalizedText).
 		Set("author_id", caption.AuthorID).
 		Set("approved", caption.Approved).
 		Set("created_at", caption.CreatedAt).
@@ -165,6 +273,10 @@ func (storage *captionStorage) Update(ctx context.Context, caption model.Caption
 
 	_, err = storage.client.Exec(ctx, q, args...)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return apperror.AlreadyExists.WithError(err)
+		}
+
 		return apperror.Internal.WithError(err)
 	}
 
@@ -181,10 +293,14 @@ func (storage *captionStorage) Delete(ctx context.Context, captionID uuid.UUID)
 		return apperror.Internal.WithError(err)
 	}
 
-	_, err = storage.client.Exec(ctx, q, args...)
+	tag, err := storage.client.Exec(ctx, q, args...)
 	if err != nil {
 		return apperror.Internal.WithError(err)
 	}
 
+	if tag.RowsAffected() == 0 {
+		return apperror.NotFound.WithMessage("caption not found")
+	}
+
 	return nil
 }
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"markoslav/internal/model"
+	"markoslav/pkg/apperror"
+	"markoslav/pkg/postgres"
+)
+
+type ChatSettingsStorage interface {
+	Save(ctx context.Context, settings model.ChatSettings) error
+
+	GetByChatID(ctx context.Context, chatID int64) (model.ChatSettings, error)
+}
+
+type chatSettingsStorage struct {
+	client postgres.Client
+}
+
+func NewChatSettingsStorage(client postgres.Client) ChatSettingsStorage {
+	return &chatSettingsStorage{client: client}
+}
+
+func (storage *chatSettingsStorage) Save(ctx context.Context, settings model.ChatSettings) error {
+	builder := squirrel.Insert("chat_settings").
+		Columns("chat_id", "image_format", "text_style", "custom_captions_disabled", "updated_by", "updated_at").
+		Values(
+			settings.ChatID, settings.ImageFormat, settings.TextStyle, settings.CustomCaptionsDisabled,
+			settings.UpdatedBy, settings.UpdatedAt,
+		).
+		Suffix(`ON CONFLICT (chat_id) DO UPDATE SET image_format = EXCLUDED.image_format,
+			text_style = EXCLUDED.text_style, custom_captions_disabled = EXCLUDED.custom_captions_disabled,
+			updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at`).
+		PlaceholderFormat(squirrel.Dollar)
+
+	q, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	_, err = storage.client.Exec(ctx, q, args...)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *chatSettingsStorage) GetByChatID(ctx context.Context, chatID int64) (model.ChatSettings, error) {
+	builder := squirrel.Select("chat_id", "image_format", "text_style", "custom_captions_disabled", "updated_by", "updated_at").
+		From("chat_settings").
+		Where(squirrel.Eq{"chat_id": chatID}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	q, args, err := builder.ToSql()
+	if err != nil {
+		return model.ChatSettings{}, apperror.Internal.WithError(err)
+	}
+
+	var settings model.ChatSettings
+	err = storage.client.Get(ctx, &settings, q, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.ChatSettings{}, apperror.NotFound.WithError(err)
+		}
+
+		return model.ChatSettings{}, apperror.Internal.WithError(err)
+	}
+
+	return settings, nil
+}
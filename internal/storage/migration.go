@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"context"
+	"markoslav/pkg/apperror"
+	"markoslav/pkg/postgres"
+)
+
+type MigrationStorage interface {
+	Version(ctx context.Context) (int64, error)
+}
+
+type migrationStorage struct {
+	client postgres.Client
+}
+
+func NewMigrationStorage(client postgres.Client) MigrationStorage {
+	return &migrationStorage{client: client}
+}
+
+// Version returns the latest applied goose migration version.
+func (storage *migrationStorage) Version(ctx context.Context) (int64, error) {
+	q := `SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied`
+
+	var version int64
+	err := storage.client.Get(ctx, &version, q)
+	if err != nil {
+		return 0, apperror.Internal.WithError(err)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"github.com/Masterminds/squirrel"
+	"markoslav/internal/model"
+	"markoslav/pkg/apperror"
+	"markoslav/pkg/filter"
+	"markoslav/pkg/postgres"
+)
+
+type ModerationEventStorage interface {
+	Create(ctx context.Context, event model.ModerationEvent) error
+
+	Select(ctx context.Context, count int, offset int, options filter.Options) ([]model.ModerationEvent, error)
+}
+
+type moderationEventStorage struct {
+	client postgres.Client
+}
+
+func NewModerationEventStorage(client postgres.Client) ModerationEventStorage {
+	return &moderationEventStorage{client: client}
+}
+
+func (storage *moderationEventStorage) Create(ctx context.Context, event model.ModerationEvent) error {
+	builder := squirrel.Insert("caption_moderation_event").
+		Columns("id", "caption_id", "caption_text", "moderator_id", "action", "reason", "created_at").
+		Values(event.ID, event.CaptionID, event.CaptionText, event.ModeratorID, event.Action, event.Reason, event.CreatedAt).
+		PlaceholderFormat(squirrel.Dollar)
+
+	q, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	_, err = storage.client.Exec(ctx, q, args...)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *moderationEventStorage) Select(ctx context.Context, count int, offset int, options filter.Options) ([]model.ModerationEvent, error) {
+	builder := squirrel.Select("id", "caption_id", "caption_text", "moderator_id", "action", "reason", "created_at").
+		From("caption_moderation_event").
+		OrderBy("created_at DESC").
+		Limit(uint64(count)).
+		Offset(uint64(offset)).
+		PlaceholderFormat(squirrel.Dollar)
+
+	for _, field := range options.Fields() {
+		switch field.Operator {
+		case filter.OperatorEq:
+			builder = builder.Where(squirrel.Eq{field.Name: field.Value})
+		case filter.OperatorNotEq:
+			builder = builder.Where(squirrel.NotEq{field.Name: field.Value})
+		}
+	}
+
+	q, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.Internal.WithError(err)
+	}
+
+	var events []model.ModerationEvent
+	err = storage.client.Select(ctx, &events, q, args...)
+	if err != nil {
+		return nil, apperror.Internal.WithError(err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/pkg/apperror"
+	"markoslav/pkg/postgres"
+)
+
+type RenderedImageStorage interface {
+	Save(ctx context.Context, renderedImage model.RenderedImage) error
+
+	Get(ctx context.Context, key dto.RenderedImageKey) (model.RenderedImage, error)
+
+	DeleteByCaptionID(ctx context.Context, captionID uuid.UUID) error
+}
+
+type renderedImageStorage struct {
+	client postgres.Client
+}
+
+func NewRenderedImageStorage(client postgres.Client) RenderedImageStorage {
+	return &renderedImageStorage{client: client}
+}
+
+func (storage *renderedImageStorage) Save(ctx context.Context, renderedImage model.RenderedImage) error {
+	builder := squirrel.Insert("rendered_image").
+		Columns("source_file_unique_id", "caption_id", "style", "file_id", "created_at").
+		Values(
+			renderedImage.SourceFileUniqueID, renderedImage.CaptionID, renderedImage.Style,
+			renderedImage.FileID, renderedImage.CreatedAt,
+		).
+		Suffix("ON CONFLICT (source_file_unique_id, caption_id, style) DO UPDATE SET file_id = EXCLUDED.file_id").
+		PlaceholderFormat(squirrel.Dollar)
+
+	q, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	_, err = storage.client.Exec(ctx, q, args...)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
+
+func (storage *renderedImageStorage) Get(ctx context.Context, key dto.RenderedImageKey) (model.RenderedImage, error) {
+	builder := squirrel.Select("source_file_unique_id", "caption_id", "style", "file_id", "created_at").
+		From("rendered_image").
+		Where(squirrel.Eq{
+			"source_file_unique_id": key.SourceFileUniqueID,
+			"caption_id":            key.CaptionID,
+			"style":                 key.Style,
+		}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	q, args, err := builder.ToSql()
+	if err != nil {
+		return model.RenderedImage{}, apperror.Internal.WithError(err)
+	}
+
+	var renderedImage model.RenderedImage
+	err = storage.client.Get(ctx, &renderedImage, q, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.RenderedImage{}, apperror.NotFound.WithError(err)
+		}
+
+		return model.RenderedImage{}, apperror.Internal.WithError(err)
+	}
+
+	return renderedImage, nil
+}
+
+func (storage *renderedImageStorage) DeleteByCaptionID(ctx context.Context, captionID uuid.UUID) error {
+	builder := squirrel.Delete("rendered_image").
+		Where(squirrel.Eq{"caption_id": captionID}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	q, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	_, err = storage.client.Exec(ctx, q, args...)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"markoslav/internal/model"
+	"markoslav/pkg/apperror"
+	"markoslav/pkg/postgres"
+)
+
+// UpdateOffsetStorage keeps the ID of the last processed Telegram update.
+type UpdateOffsetStorage interface {
+	// Get returns a zero offset if no update was processed yet.
+	Get(ctx context.Context) (model.UpdateOffset, error)
+
+	Save(ctx context.Context, updateID int) error
+}
+
+type updateOffsetStorage struct {
+	client postgres.Client
+}
+
+func NewUpdateOffsetStorage(client postgres.Client) UpdateOffsetStorage {
+	return &updateOffsetStorage{client: client}
+}
+
+func (storage *updateOffsetStorage) Get(ctx context.Context) (model.UpdateOffset, error) {
+	q := `SELECT last_update_id, updated_at FROM bot_update_offset WHERE id = 1`
+
+	var offset model.UpdateOffset
+	err := storage.client.Get(ctx, &offset, q)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.UpdateOffset{}, nil
+		}
+
+		return model.UpdateOffset{}, apperror.Internal.WithError(err)
+	}
+
+	return offset, nil
+}
+
+func (storage *updateOffsetStorage) Save(ctx context.Context, updateID int) error {
+	q := `INSERT INTO bot_update_offset (id, last_update_id) VALUES (1, $1)
+		ON CONFLICT (id) DO UPDATE SET last_update_id = EXCLUDED.last_update_id, updated_at = now()`
+
+	_, err := storage.client.Exec(ctx, q, updateID)
+	if err != nil {
+		return apperror.Internal.WithError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,251 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"markoslav/internal/model"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatJSONL Format = "jsonl"
+	FormatCSV   Format = "csv"
+	FormatText  Format = "txt"
+)
+
+var ErrUnknownFormat = errors.New("unknown format")
+
+// FormatFromPath guesses the format by file extension, falling back to plain text.
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".json":
+		return FormatJSONL
+	case ".csv":
+		return FormatCSV
+	default:
+		return FormatText
+	}
+}
+
+type CaptionRecord struct {
+	ID        string    `json:"id,omitempty"`
+	Text      string    `json:"text"`
+	AuthorID  int64     `json:"author_id,omitempty"`
+	Approved  bool      `json:"approved,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// Invalid marks rows with malformed cells, which importers count and skip.
+	Invalid bool `json:"-"`
+}
+
+func ReadCaptions(r io.Reader, format Format) ([]CaptionRecord, error) {
+	switch format {
+	case FormatJSONL:
+		return readJSONL(r)
+	case FormatCSV:
+		return readCSV(r)
+	case FormatText:
+		return readText(r)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
+
+func readJSONL(r io.Reader) ([]CaptionRecord, error) {
+	var records []CaptionRecord
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		data := strings.TrimSpace(scanner.Text())
+		if data == "" {
+			continue
+		}
+
+		var record CaptionRecord
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+func readCSV(r io.Reader) ([]CaptionRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	// Without a header row the first column is treated as caption text.
+	columns := map[string]int{"text": 0}
+	if header := rows[0]; indexOf(header, "text") >= 0 {
+		columns = make(map[string]int, len(header))
+		for i, name := range header {
+			columns[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+
+		rows = rows[1:]
+	}
+
+	records := make([]CaptionRecord, 0, len(rows))
+	for _, row := range rows {
+		record := CaptionRecord{Text: column(row, columns, "text")}
+		if record.Text == "" {
+			continue
+		}
+
+		if value := column(row, columns, "author_id"); value != "" {
+			if record.AuthorID, err = strconv.ParseInt(value, 10, 64); err != nil {
+				record.Invalid = true
+			}
+		}
+
+		if value := column(row, columns, "approved"); value != "" {
+			if record.Approved, err = strconv.ParseBool(value); err != nil {
+				record.Invalid = true
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func readText(r io.Reader) ([]CaptionRecord, error) {
+	var records []CaptionRecord
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		records = append(records, CaptionRecord{Text: text})
+	}
+
+	return records, scanner.Err()
+}
+
+// CaptionWriter writes captions one by one in the chosen format.
+type CaptionWriter interface {
+	Write(caption model.Caption) error
+	Flush() error
+}
+
+func NewCaptionWriter(w io.Writer, format Format) (CaptionWriter, error) {
+	switch format {
+	case FormatJSONL:
+		return &jsonlWriter{encoder: json.NewEncoder(w)}, nil
+	case FormatCSV:
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	case FormatText:
+		return &textWriter{writer: bufio.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
+
+type jsonlWriter struct {
+	encoder *json.Encoder
+}
+
+func (writer *jsonlWriter) Write(caption model.Caption) error {
+	return writer.encoder.Encode(CaptionRecord{
+		ID:        caption.ID.String(),
+		Text:      caption.Text,
+		AuthorID:  caption.AuthorID,
+		Approved:  caption.Approved,
+		CreatedAt: caption.CreatedAt,
+	})
+}
+
+func (writer *jsonlWriter) Flush() error {
+	return nil
+}
+
+type csvWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func (writer *csvWriter) Write(caption model.Caption) error {
+	if !writer.headerWritten {
+		if err := writer.writer.Write([]string{"id", "text", "author_id", "approved", "created_at"}); err != nil {
+			return err
+		}
+
+		writer.headerWritten = true
+	}
+
+	return writer.writer.Write([]string{
+		caption.ID.String(),
+		caption.Text,
+		strconv.FormatInt(caption.AuthorID, 10),
+		strconv.FormatBool(caption.Approved),
+		caption.CreatedAt.Format(time.RFC3339),
+	})
+}
+
+func (writer *csvWriter) Flush() error {
+	writer.writer.Flush()
+
+	return writer.writer.Error()
+}
+
+type textWriter struct {
+	writer *bufio.Writer
+}
+
+func (writer *textWriter) Write(caption model.Caption) error {
+	// Multiline captions are joined so that every caption stays on its own line.
+	_, err := writer.writer.WriteString(strings.ReplaceAll(caption.Text, "\n", " ") + "\n")
+
+	return err
+}
+
+func (writer *textWriter) Flush() error {
+	return writer.writer.Flush()
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func column(row []string, columns map[string]int, name string) string {
+	i, ok := columns[name]
+	if !ok || i >= len(row) {
+		return ""
+	}
+
+	return strings.TrimSpace(row[i])
+}
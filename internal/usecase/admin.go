@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/service"
+)
+
+type AdminUsecase interface {
+	Add(ctx context.Context, request dto.AddAdmin) (model.Admin, error)
+	Remove(ctx context.Context, userID int64) error
+
+	GetRole(ctx context.Context, userID int64) (model.AdminRole, error)
+
+	Select(ctx context.Context) ([]model.Admin, error)
+}
+
+type adminUsecase struct {
+	adminService service.AdminService
+}
+
+func NewAdminUsecase(adminService service.AdminService) AdminUsecase {
+	return &adminUsecase{adminService: adminService}
+}
+
+func (usecase *adminUsecase) Add(ctx context.Context, request dto.AddAdmin) (model.Admin, error) {
+	return usecase.adminService.Add(ctx, request)
+}
+
+func (usecase *adminUsecase) Remove(ctx context.Context, userID int64) error {
+	return usecase.adminService.Remove(ctx, userID)
+}
+
+func (usecase *adminUsecase) GetRole(ctx context.Context, userID int64) (model.AdminRole, error) {
+	return usecase.adminService.GetRole(ctx, userID)
+}
+
+func (usecase *adminUsecase) Select(ctx context.Context) ([]model.Admin, error) {
+	return usecase.adminService.Select(ctx)
+}
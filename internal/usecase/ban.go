@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"context"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/service"
+	"markoslav/pkg/apperror"
+)
+
+type BanUsecase interface {
+	Ban(ctx context.Context, request dto.CreateBan) (model.Ban, error)
+	Unban(ctx context.Context, userID int64) error
+}
+
+type banUsecase struct {
+	banService   service.BanService
+	adminService service.AdminService
+}
+
+func NewBanUsecase(banService service.BanService, adminService service.AdminService) BanUsecase {
+	return &banUsecase{banService: banService, adminService: adminService}
+}
+
+func (usecase *banUsecase) Ban(ctx context.Context, request dto.CreateBan) (model.Ban, error) {
+	role, err := usecase.adminService.GetRole(ctx, request.UserID)
+	if err != nil {
+		return model.Ban{}, err
+	}
+
+	if role != model.AdminRoleNone {
+		return model.Ban{}, apperror.Forbidden.WithMessage("admin can't be banned")
+	}
+
+	return usecase.banService.Ban(ctx, request)
+}
+
+func (usecase *banUsecase) Unban(ctx context.Context, userID int64) error {
+	return usecase.banService.Unban(ctx, userID)
+}
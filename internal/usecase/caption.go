@@ -2,52 +2,197 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"github.com/google/uuid"
 	"image"
 	"markoslav/internal/dto"
 	"markoslav/internal/model"
 	"markoslav/internal/service"
+	"markoslav/pkg/apperror"
 	"markoslav/pkg/filter"
+	"markoslav/pkg/ratelimit"
+	"time"
 )
 
 type CaptionUsecase interface {
 	Create(ctx context.Context, request dto.CreateCaption) (model.Caption, error)
+	Add(ctx context.Context, request dto.CreateCaption) (model.Caption, error)
+	Import(ctx context.Context, requests []dto.CreateCaption) (dto.ImportCaptionsResult, error)
 
-	Approve(ctx context.Context, captionID uuid.UUID) error
-	Reject(ctx context.Context, captionID uuid.UUID) error
+	GetByID(ctx context.Context, captionID uuid.UUID) (model.Caption, error)
+
+	Approve(ctx context.Context, request dto.ModerateCaption) error
+	Reject(ctx context.Context, request dto.ModerateCaption) error
+	Edit(ctx context.Context, request dto.EditCaption) (model.Caption, error)
+	Delete(ctx context.Context, request dto.ModerateCaption) error
 
 	Select(ctx context.Context, count int, offset int, options filter.Options) ([]model.Caption, error)
+	SelectSimilar(ctx context.Context, caption model.Caption, count int) ([]model.Caption, error)
 
-	DrawRandom(ctx context.Context, img image.Image) (image.Image, error)
+	GetRandom(ctx context.Context) (model.Caption, error)
+	// Custom builds an unsaved caption from user text, checked like a suggestion.
+	Custom(ctx context.Context, request dto.CustomCaption) (model.Caption, error)
+	Draw(ctx context.Context, caption model.Caption, img image.Image, options dto.DrawOptions) (image.Image, error)
 }
 
 type captionUsecase struct {
-	captionService service.CaptionService
-	imageService   service.ImageService
+	captionService         service.CaptionService
+	moderationEventService service.ModerationEventService
+	banService             service.BanService
+	imageService           service.ImageService
+	suggestLimiter         *ratelimit.Window
+	customLimiter          *ratelimit.Window
 }
 
-func NewCaptionUsecase(captionService service.CaptionService, imageService service.ImageService) CaptionUsecase {
-	return &captionUsecase{captionService: captionService, imageService: imageService}
+func NewCaptionUsecase(
+	captionService service.CaptionService,
+	moderationEventService service.ModerationEventService,
+	banService service.BanService,
+	imageService service.ImageService,
+	suggestLimiter *ratelimit.Window,
+	customLimiter *ratelimit.Window,
+) CaptionUsecase {
+	return &captionUsecase{
+		captionService:         captionService,
+		moderationEventService: moderationEventService,
+		banService:             banService,
+		imageService:           imageService,
+		suggestLimiter:         suggestLimiter,
+		customLimiter:          customLimiter,
+	}
 }
 
 func (usecase *captionUsecase) Create(ctx context.Context, request dto.CreateCaption) (model.Caption, error) {
+	banned, err := usecase.banService.IsBanned(ctx, request.AuthorID)
+	if err != nil {
+		return model.Caption{}, err
+	}
+
+	if banned {
+		return model.Caption{}, apperror.Forbidden.WithMessage("author is banned")
+	}
+
+	suggestedAt, ok, retryAfter := usecase.suggestLimiter.Allow(request.AuthorID)
+	if !ok {
+		return model.Caption{}, apperror.TooManyRequests.
+			WithMessage(fmt.Sprintf("suggestion limit exceeded, retry after %s", retryAfter.Round(time.Minute)))
+	}
+
+	caption, err := usecase.captionService.Create(ctx, request)
+	if err != nil {
+		// Only created suggestions count towards the limit.
+		usecase.suggestLimiter.Release(request.AuthorID, suggestedAt)
+		return model.Caption{}, err
+	}
+
+	return caption, nil
+}
+
+// Add creates a caption on behalf of an admin, bypassing bans and rate limits.
+func (usecase *captionUsecase) Add(ctx context.Context, request dto.CreateCaption) (model.Caption, error) {
 	return usecase.captionService.Create(ctx, request)
 }
 
-func (usecase *captionUsecase) Approve(ctx context.Context, captionID uuid.UUID) error {
+// Import creates captions bypassing bans and rate limits, skipping duplicates and invalid texts.
+func (usecase *captionUsecase) Import(ctx context.Context, requests []dto.CreateCaption) (dto.ImportCaptionsResult, error) {
+	return usecase.captionService.CreateMany(ctx, requests)
+}
+
+func (usecase *captionUsecase) GetByID(ctx context.Context, captionID uuid.UUID) (model.Caption, error) {
+	return usecase.captionService.GetByID(ctx, captionID)
+}
+
+func (usecase *captionUsecase) Approve(ctx context.Context, request dto.ModerateCaption) error {
+	approved := true
+
 	err := usecase.captionService.Update(ctx, dto.UpdateCaption{
-		ID:       captionID,
-		Approved: true,
+		ID:       request.ID,
+		Approved: &approved,
 	})
 	if err != nil {
 		return err
 	}
 
-	return nil
+	caption, err := usecase.captionService.GetByID(ctx, request.ID)
+	if err != nil {
+		return err
+	}
+
+	return usecase.logModeration(ctx, caption, request.ModeratorID, model.ModerationActionApprove, request.Reason)
 }
 
-func (usecase *captionUsecase) Reject(ctx context.Context, captionID uuid.UUID) error {
-	err := usecase.captionService.Delete(ctx, captionID)
+func (usecase *captionUsecase) Reject(ctx context.Context, request dto.ModerateCaption) error {
+	caption, err := usecase.captionService.GetByID(ctx, request.ID)
+	if err != nil {
+		return err
+	}
+
+	err = usecase.captionService.Delete(ctx, request.ID)
+	if err != nil {
+		return err
+	}
+
+	return usecase.logModeration(ctx, caption, request.ModeratorID, model.ModerationActionReject, request.Reason)
+}
+
+func (usecase *captionUsecase) Edit(ctx context.Context, request dto.EditCaption) (model.Caption, error) {
+	original, err := usecase.captionService.GetByID(ctx, request.ID)
+	if err != nil {
+		return model.Caption{}, err
+	}
+
+	err = usecase.captionService.Update(ctx, dto.UpdateCaption{
+		ID:   request.ID,
+		Text: &request.Text,
+	})
+	if err != nil {
+		return model.Caption{}, err
+	}
+
+	caption, err := usecase.captionService.GetByID(ctx, request.ID)
+	if err != nil {
+		return model.Caption{}, err
+	}
+
+	reason := fmt.Sprintf("%s → %s", original.Text, caption.Text)
+
+	err = usecase.logModeration(ctx, caption, request.ModeratorID, model.ModerationActionEdit, reason)
+	if err != nil {
+		return model.Caption{}, err
+	}
+
+	return caption, nil
+}
+
+// Delete removes a caption through the API. The event is logged first, so no deletion is missing from the log.
+func (usecase *captionUsecase) Delete(ctx context.Context, request dto.ModerateCaption) error {
+	caption, err := usecase.captionService.GetByID(ctx, request.ID)
+	if err != nil {
+		return err
+	}
+
+	err = usecase.logModeration(ctx, caption, request.ModeratorID, model.ModerationActionDelete, request.Reason)
+	if err != nil {
+		return err
+	}
+
+	return usecase.captionService.Delete(ctx, request.ID)
+}
+
+func (usecase *captionUsecase) logModeration(
+	ctx context.Context,
+	caption model.Caption,
+	moderatorID int64,
+	action model.ModerationAction,
+	reason string,
+) error {
+	_, err := usecase.moderationEventService.Create(ctx, dto.CreateModerationEvent{
+		CaptionID:   caption.ID,
+		CaptionText: caption.Text,
+		ModeratorID: moderatorID,
+		Action:      action,
+		Reason:      reason,
+	})
 	if err != nil {
 		return err
 	}
@@ -59,11 +204,45 @@ func (usecase *captionUsecase) Select(ctx context.Context, count int, offset int
 	return usecase.captionService.Select(ctx, count, offset, options)
 }
 
-func (usecase *captionUsecase) DrawRandom(ctx context.Context, img image.Image) (image.Image, error) {
-	caption, err := usecase.captionService.GetRandom(ctx)
+func (usecase *captionUsecase) SelectSimilar(ctx context.Context, caption model.Caption, count int) ([]model.Caption, error) {
+	return usecase.captionService.SelectSimilar(ctx, caption, count)
+}
+
+func (usecase *captionUsecase) GetRandom(ctx context.Context) (model.Caption, error) {
+	return usecase.captionService.GetRandom(ctx)
+}
+
+func (usecase *captionUsecase) Custom(ctx context.Context, request dto.CustomCaption) (model.Caption, error) {
+	banned, err := usecase.banService.IsBanned(ctx, request.AuthorID)
+	if err != nil {
+		return model.Caption{}, err
+	}
+
+	if banned {
+		return model.Caption{}, apperror.Forbidden.WithMessage("author is banned")
+	}
+
+	// Every custom caption is rendered, so they are limited separately from suggestions.
+	requestedAt, ok, retryAfter := usecase.customLimiter.Allow(request.AuthorID)
+	if !ok {
+		return model.Caption{}, apperror.TooManyRequests.
+			WithMessage(fmt.Sprintf("custom caption limit exceeded, retry after %s", retryAfter.Round(time.Second)))
+	}
+
+	caption, err := usecase.captionService.Parse(request.Text)
 	if err != nil {
-		return nil, err
+		usecase.customLimiter.Release(request.AuthorID, requestedAt)
+		return model.Caption{}, err
 	}
 
-	return usecase.imageService.Draw(ctx, caption, img)
+	caption.AuthorID = request.AuthorID
+	caption.CreatedAt = time.Now()
+
+	return caption, nil
+}
+
+func (usecase *captionUsecase) Draw(
+	ctx context.Context, caption model.Caption, img image.Image, options dto.DrawOptions,
+) (image.Image, error) {
+	return usecase.imageService.Draw(ctx, caption, img, options)
 }
@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"context"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/service"
+)
+
+type ChatSettingsUsecase interface {
+	Get(ctx context.Context, chatID int64) (model.ChatSettings, error)
+	Update(ctx context.Context, request dto.UpdateChatSettings) (model.ChatSettings, error)
+}
+
+type chatSettingsUsecase struct {
+	chatSettingsService service.ChatSettingsService
+}
+
+func NewChatSettingsUsecase(chatSettingsService service.ChatSettingsService) ChatSettingsUsecase {
+	return &chatSettingsUsecase{chatSettingsService: chatSettingsService}
+}
+
+func (usecase *chatSettingsUsecase) Get(ctx context.Context, chatID int64) (model.ChatSettings, error) {
+	return usecase.chatSettingsService.Get(ctx, chatID)
+}
+
+func (usecase *chatSettingsUsecase) Update(ctx context.Context, request dto.UpdateChatSettings) (model.ChatSettings, error) {
+	return usecase.chatSettingsService.Update(ctx, request)
+}
@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"context"
+	"image"
+	"markoslav/internal/dto"
+	"markoslav/internal/service"
+)
+
+type DownloadUsecase interface {
+	Download(ctx context.Context, request dto.Download) ([]byte, error)
+	DownloadImage(ctx context.Context, request dto.Download) (image.Image, error)
+}
+
+type downloadUsecase struct {
+	downloadService service.DownloadService
+}
+
+func NewDownloadUsecase(downloadService service.DownloadService) DownloadUsecase {
+	return &downloadUsecase{downloadService: downloadService}
+}
+
+func (usecase *downloadUsecase) Download(ctx context.Context, request dto.Download) ([]byte, error) {
+	return usecase.downloadService.Download(ctx, request)
+}
+
+func (usecase *downloadUsecase) DownloadImage(ctx context.Context, request dto.Download) (image.Image, error) {
+	return usecase.downloadService.DownloadImage(ctx, request)
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"context"
+	"image"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/service"
+)
+
+type ImageUsecase interface {
+	Encode(ctx context.Context, img image.Image, format model.ImageFormat) (dto.EncodedImage, error)
+}
+
+type imageUsecase struct {
+	encoderService service.EncoderService
+}
+
+func NewImageUsecase(encoderService service.EncoderService) ImageUsecase {
+	return &imageUsecase{encoderService: encoderService}
+}
+
+func (usecase *imageUsecase) Encode(ctx context.Context, img image.Image, format model.ImageFormat) (dto.EncodedImage, error) {
+	return usecase.encoderService.Encode(ctx, img, format)
+}
@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"context"
+	"markoslav/internal/model"
+	"markoslav/internal/service"
+	"markoslav/pkg/filter"
+)
+
+type ModerationEventUsecase interface {
+	Select(ctx context.Context, count int, offset int, options filter.Options) ([]model.ModerationEvent, error)
+}
+
+type moderationEventUsecase struct {
+	moderationEventService service.ModerationEventService
+}
+
+func NewModerationEventUsecase(moderationEventService service.ModerationEventService) ModerationEventUsecase {
+	return &moderationEventUsecase{moderationEventService: moderationEventService}
+}
+
+func (usecase *moderationEventUsecase) Select(ctx context.Context, count int, offset int, options filter.Options) ([]model.ModerationEvent, error) {
+	return usecase.moderationEventService.Select(ctx, count, offset, options)
+}
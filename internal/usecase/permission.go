@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"markoslav/internal/dto"
+	"markoslav/internal/model"
+	"markoslav/internal/service"
+)
+
+type PermissionUsecase interface {
+	Check(ctx context.Context, request dto.CheckPermission) (bool, error)
+}
+
+type permissionUsecase struct {
+	adminService      service.AdminService
+	chatMemberService service.ChatMemberService
+}
+
+func NewPermissionUsecase(adminService service.AdminService, chatMemberService service.ChatMemberService) PermissionUsecase {
+	return &permissionUsecase{adminService: adminService, chatMemberService: chatMemberService}
+}
+
+func (usecase *permissionUsecase) Check(ctx context.Context, request dto.CheckPermission) (bool, error) {
+	role, err := usecase.adminService.GetRole(ctx, request.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	if role.Can(request.Permission) {
+		return true, nil
+	}
+
+	// Telegram group admins are allowed to manage settings of their own chat.
+	if request.Permission == model.PermissionManageChat && request.ChatID != request.UserID {
+		return usecase.chatMemberService.IsChatAdmin(ctx, request.ChatID, request.UserID)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+	"markoslav/internal/dto"
+	"markoslav/internal/service"
+)
+
+type RenderedImageUsecase interface {
+	Save(ctx context.Context, request dto.SaveRenderedImage) error
+	GetFileID(ctx context.Context, key dto.RenderedImageKey) (string, error)
+}
+
+type renderedImageUsecase struct {
+	renderedImageService service.RenderedImageService
+}
+
+func NewRenderedImageUsecase(renderedImageService service.RenderedImageService) RenderedImageUsecase {
+	return &renderedImageUsecase{renderedImageService: renderedImageService}
+}
+
+func (usecase *renderedImageUsecase) Save(ctx context.Context, request dto.SaveRenderedImage) error {
+	return usecase.renderedImageService.Save(ctx, request)
+}
+
+func (usecase *renderedImageUsecase) GetFileID(ctx context.Context, key dto.RenderedImageKey) (string, error) {
+	return usecase.renderedImageService.GetFileID(ctx, key)
+}
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS caption_moderation_event
+(
+    id           UUID PRIMARY KEY     DEFAULT GEN_RANDOM_UUID(),
+    caption_id   UUID        NOT NULL,
+    caption_text TEXT        NOT NULL,
+    moderator_id BIGINT      NOT NULL,
+    action       TEXT        NOT NULL,
+    reason       TEXT        NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS caption_moderation_event_created_at_idx ON caption_moderation_event (created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS caption_moderation_event;
+-- +goose StatementEnd
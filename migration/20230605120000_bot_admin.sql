@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS bot_admin
+(
+    user_id    BIGINT PRIMARY KEY,
+    role       TEXT        NOT NULL,
+    added_by   BIGINT      NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS bot_admin;
+-- +goose StatementEnd
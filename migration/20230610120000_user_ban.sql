@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_ban
+(
+    user_id    BIGINT PRIMARY KEY,
+    reason     TEXT        NOT NULL DEFAULT '',
+    banned_by  BIGINT      NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_ban;
+-- +goose StatementEnd
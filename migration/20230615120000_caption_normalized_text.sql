@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE caption ADD COLUMN IF NOT EXISTS normalized_text TEXT;
+
+UPDATE caption
+SET normalized_text = btrim(regexp_replace(
+        regexp_replace(replace(lower(text), 'ё', 'е'), '[^[:alnum:][:space:]]+', '', 'g'),
+        '\s+', ' ', 'g'));
+
+-- Near-duplicates suggested before normalisation existed stay in place but must not break the unique index.
+UPDATE caption c
+SET normalized_text = c.normalized_text || ' #' || c.id
+WHERE EXISTS (SELECT 1
+              FROM caption o
+              WHERE o.normalized_text = c.normalized_text
+                AND (o.created_at, o.id) < (c.created_at, c.id));
+
+ALTER TABLE caption ALTER COLUMN normalized_text SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS caption_normalized_text_idx ON caption (normalized_text);
+CREATE INDEX IF NOT EXISTS caption_normalized_text_trgm_idx ON caption USING gin (normalized_text gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS caption_normalized_text_trgm_idx;
+DROP INDEX IF EXISTS caption_normalized_text_idx;
+ALTER TABLE caption DROP COLUMN IF EXISTS normalized_text;
+-- +goose StatementEnd
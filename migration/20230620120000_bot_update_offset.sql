@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS bot_update_offset
+(
+    id             SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    last_update_id BIGINT      NOT NULL,
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS bot_update_offset;
+-- +goose StatementEnd
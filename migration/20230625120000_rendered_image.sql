@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS rendered_image
+(
+    source_file_unique_id TEXT        NOT NULL,
+    caption_id            UUID        NOT NULL REFERENCES caption (id) ON DELETE CASCADE,
+    style                 TEXT        NOT NULL,
+    file_id               TEXT        NOT NULL,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (source_file_unique_id, caption_id, style)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS rendered_image;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS chat_settings
+(
+    chat_id      BIGINT PRIMARY KEY,
+    image_format TEXT        NOT NULL DEFAULT '',
+    updated_by   BIGINT      NOT NULL,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS chat_settings;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE caption ADD COLUMN IF NOT EXISTS top_text TEXT NOT NULL DEFAULT '';
+ALTER TABLE caption ADD COLUMN IF NOT EXISTS bottom_text TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE caption DROP COLUMN IF EXISTS bottom_text;
+ALTER TABLE caption DROP COLUMN IF EXISTS top_text;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Captions with a single "|" suggested before two-part captions existed get their parts and the " | " spelling.
+UPDATE caption
+SET top_text    = btrim(split_part(text, '|', 1), E' \n'),
+    bottom_text = btrim(split_part(text, '|', 2), E' \n'),
+    text        = btrim(split_part(text, '|', 1), E' \n') || ' | ' || btrim(split_part(text, '|', 2), E' \n')
+WHERE top_text = ''
+  AND text LIKE '%|%'
+  AND text NOT LIKE '%|%|%'
+  AND btrim(split_part(text, '|', 1), E' \n') <> ''
+  AND btrim(split_part(text, '|', 2), E' \n') <> '';
+
+-- The separator now counts as a space in normalised texts. Texts with it are first made unique by their IDs,
+-- so the unique index does not fail while they are renormalised.
+UPDATE caption
+SET normalized_text = normalized_text || ' #' || id
+WHERE text LIKE '%|%';
+
+WITH renormalized AS (SELECT id,
+                             created_at,
+                             btrim(regexp_replace(
+                                     regexp_replace(replace(replace(lower(text), 'ё', 'е'), '|', ' '),
+                                                    '[^[:alnum:][:space:]]+', '', 'g'),
+                                     '\s+', ' ', 'g')) AS normalized_text
+                      FROM caption
+                      WHERE text LIKE '%|%'),
+     checked AS (SELECT r.id,
+                        r.normalized_text,
+                        EXISTS (SELECT 1
+                                FROM caption o
+                                WHERE o.normalized_text = r.normalized_text)
+                            OR EXISTS (SELECT 1
+                                       FROM renormalized p
+                                       WHERE p.normalized_text = r.normalized_text
+                                         AND (p.created_at, p.id) < (r.created_at, r.id)) AS duplicate
+                 FROM renormalized r)
+UPDATE caption c
+SET normalized_text = CASE WHEN checked.duplicate THEN checked.normalized_text || ' #' || c.id ELSE checked.normalized_text END
+FROM checked
+WHERE c.id = checked.id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Split and renormalised captions stay valid for the previous version.
+SELECT 1;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Captions of symbols only were normalised to "", or to " #<id>" for the later ones. They now keep
+-- their lowercased text, so only the same symbols are duplicates.
+UPDATE caption
+SET normalized_text = '#' || id
+WHERE normalized_text = ''
+   OR normalized_text = ' #' || id;
+
+WITH renormalized AS (SELECT id,
+                             created_at,
+                             lower(btrim(regexp_replace(text, '\s+', ' ', 'g'))) AS normalized_text
+                      FROM caption
+                      WHERE normalized_text = '#' || id),
+     checked AS (SELECT r.id,
+                        r.normalized_text,
+                        EXISTS (SELECT 1
+                                FROM caption o
+                                WHERE o.normalized_text = r.normalized_text)
+                            OR EXISTS (SELECT 1
+                                       FROM renormalized p
+                                       WHERE p.normalized_text = r.normalized_text
+                                         AND (p.created_at, p.id) < (r.created_at, r.id)) AS duplicate
+                 FROM renormalized r)
+UPDATE caption c
+SET normalized_text = CASE WHEN checked.duplicate THEN checked.normalized_text || ' #' || c.id ELSE checked.normalized_text END
+FROM checked
+WHERE c.id = checked.id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Lowercased texts stay valid for the previous version.
+SELECT 1;
+-- +goose StatementEnd
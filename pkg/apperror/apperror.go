@@ -36,12 +36,15 @@ func NewWithCode(code int, status string) Error {
 	}
 }
 
+// Is looks for an Error matching apperr in the chain of target and returns it.
 func Is(target error, apperr error) (err Error, ok bool) {
-	if errors.Is(target, apperr) {
-		return target.(Error), true
+	for ; target != nil; target = errors.Unwrap(target) {
+		if err, ok = target.(Error); ok && err.Is(apperr) {
+			return err, true
+		}
 	}
 
-	return
+	return Error{}, false
 }
 
 func (error Error) Error() string {
@@ -65,6 +68,10 @@ func (error Error) Is(target error) bool {
 	return error.Code == err.Code
 }
 
+func (error Error) Unwrap() error {
+	return error.Err
+}
+
 func (error Error) WithMessage(message string) Error {
 	error.Message = message
 
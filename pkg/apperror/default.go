@@ -1,11 +1,50 @@
 package apperror
 
+import "net/http"
+
 var (
-	Unknown       = New("unknown error")
-	Internal      = New("internal error")
-	NotFound      = New("not found")
-	AlreadyExists = New("already exists")
-	BadRequest    = New("bad request")
-	Unauthorized  = New("unauthorized")
-	Forbidden     = New("forbidden")
+	Unknown         = New("unknown error")
+	Internal        = New("internal error")
+	NotFound        = New("not found")
+	AlreadyExists   = New("already exists")
+	BadRequest      = New("bad request")
+	Unauthorized    = New("unauthorized")
+	Forbidden       = New("forbidden")
+	TooManyRequests = New("too many requests")
 )
+
+func init() {
+	Register(Unknown, Kind{
+		HTTPStatus: http.StatusInternalServerError,
+		Messages:   map[string]string{"ru": "Произошла непредвиденная ошибка.", "en": "An unexpected error occurred."},
+	})
+	Register(Internal, Kind{
+		HTTPStatus: http.StatusInternalServerError,
+		Messages:   map[string]string{"ru": "Произошла непредвиденная ошибка.", "en": "An unexpected error occurred."},
+	})
+	Register(NotFound, Kind{
+		HTTPStatus: http.StatusNotFound,
+		Messages:   map[string]string{"ru": "Ничего не найдено.", "en": "Nothing was found."},
+	})
+	Register(AlreadyExists, Kind{
+		HTTPStatus: http.StatusConflict,
+		Messages:   map[string]string{"ru": "Такая запись уже существует.", "en": "It already exists."},
+	})
+	Register(BadRequest, Kind{
+		HTTPStatus: http.StatusBadRequest,
+		Messages:   map[string]string{"ru": "Некорректный запрос.", "en": "The request is invalid."},
+		Expose:     true,
+	})
+	Register(Unauthorized, Kind{
+		HTTPStatus: http.StatusUnauthorized,
+		Messages:   map[string]string{"ru": "Требуется авторизация.", "en": "Authorization is required."},
+	})
+	Register(Forbidden, Kind{
+		HTTPStatus: http.StatusForbidden,
+		Messages:   map[string]string{"ru": "Недостаточно прав.", "en": "Access is denied."},
+	})
+	Register(TooManyRequests, Kind{
+		HTTPStatus: http.StatusTooManyRequests,
+		Messages:   map[string]string{"ru": "Слишком много запросов. Попробуйте позже.", "en": "Too many requests. Try again later."},
+	})
+}
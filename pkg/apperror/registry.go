@@ -0,0 +1,86 @@
+package apperror
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const DefaultLanguage = "ru"
+
+// Kind describes how an error is presented outside the application.
+type Kind struct {
+	HTTPStatus int
+	// Messages are user-facing messages by language code.
+	Messages map[string]string
+	// Expose means that Error.Message is written for users and may be shown as is.
+	Expose bool
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[Code]Kind)
+)
+
+func Register(err Error, kind Kind) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[err.Code] = kind
+}
+
+// As finds the first Error in the chain. Errors of other types are treated as Internal.
+func As(err error) Error {
+	var apperr Error
+	if errors.As(err, &apperr) {
+		return apperr
+	}
+
+	return Internal.WithError(err)
+}
+
+func kindOf(err error) (Error, Kind) {
+	apperr := As(err)
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	kind, ok := registry[apperr.Code]
+	if !ok {
+		kind = registry[Internal.Code]
+	}
+
+	return apperr, kind
+}
+
+func HTTPStatus(err error) int {
+	_, kind := kindOf(err)
+	if kind.HTTPStatus == 0 {
+		return http.StatusInternalServerError
+	}
+
+	return kind.HTTPStatus
+}
+
+// UserMessage returns a message describing err that is safe to show to users.
+// Language is a language tag or an Accept-Language value, only its first primary subtag is used.
+// Unknown languages fall back to DefaultLanguage.
+func UserMessage(err error, language string) string {
+	apperr, kind := kindOf(err)
+
+	if kind.Expose && apperr.Message != "" {
+		return apperr.Message
+	}
+
+	language = strings.ToLower(strings.TrimSpace(language))
+	if i := strings.IndexAny(language, "-_,;"); i >= 0 {
+		language = language[:i]
+	}
+
+	if message, ok := kind.Messages[language]; ok {
+		return message
+	}
+
+	return kind.Messages[DefaultLanguage]
+}
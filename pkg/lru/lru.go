@@ -0,0 +1,96 @@
+package lru
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache keeps the most recently used values whose total weight is up to size.
+type Cache[K comparable, V any] struct {
+	size   int64
+	weight func(V) int64
+
+	mu      sync.Mutex
+	entries map[K]*list.Element
+	order   *list.List
+	used    int64
+}
+
+type entry[K comparable, V any] struct {
+	key    K
+	value  V
+	weight int64
+}
+
+// New creates a cache of up to size values.
+func New[K comparable, V any](size int) *Cache[K, V] {
+	return NewWeighted[K, V](int64(size), func(V) int64 { return 1 })
+}
+
+// NewWeighted creates a cache of values whose total weight is up to size, e.g. in bytes.
+// Values heavier than size are not kept.
+func NewWeighted[K comparable, V any](size int64, weight func(V) int64) *Cache[K, V] {
+	return &Cache[K, V]{
+		size:    size,
+		weight:  weight,
+		entries: make(map[K]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (cache *Cache[K, V]) Get(key K) (V, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	element, ok := cache.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	cache.order.MoveToFront(element)
+
+	return element.Value.(*entry[K, V]).value, true
+}
+
+// Add stores the value, evicting the least recently used ones when the cache is full.
+func (cache *Cache[K, V]) Add(key K, value V) {
+	if cache.size <= 0 {
+		return
+	}
+
+	weight := cache.weight(value)
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	// A value heavier than the whole cache would only evict the others, the old value of the key is dropped.
+	if weight > cache.size {
+		if element, ok := cache.entries[key]; ok {
+			cache.remove(element)
+		}
+		return
+	}
+
+	if element, ok := cache.entries[key]; ok {
+		e := element.Value.(*entry[K, V])
+		cache.used += weight - e.weight
+		e.value, e.weight = value, weight
+		cache.order.MoveToFront(element)
+	} else {
+		cache.entries[key] = cache.order.PushFront(&entry[K, V]{key: key, value: value, weight: weight})
+		cache.used += weight
+	}
+
+	for cache.used > cache.size {
+		cache.remove(cache.order.Back())
+	}
+}
+
+func (cache *Cache[K, V]) remove(element *list.Element) {
+	cache.order.Remove(element)
+
+	e := element.Value.(*entry[K, V])
+	delete(cache.entries, e.key)
+	cache.used -= e.weight
+}
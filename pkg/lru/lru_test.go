@@ -0,0 +1,80 @@
+package lru
+
+import "testing"
+
+func TestCache(t *testing.T) {
+	type op struct {
+		get   bool
+		key   string
+		value int
+	}
+
+	tests := []struct {
+		name string
+		size int
+		ops  []op
+		want map[string]int
+	}{
+		{"keeps up to size", 2, []op{{key: "a", value: 1}, {key: "b", value: 2}}, map[string]int{"a": 1, "b": 2}},
+		{"evicts the oldest", 2, []op{{key: "a", value: 1}, {key: "b", value: 2}, {key: "c", value: 3}}, map[string]int{"b": 2, "c": 3}},
+		{"get refreshes", 2, []op{{key: "a", value: 1}, {key: "b", value: 2}, {get: true, key: "a"}, {key: "c", value: 3}}, map[string]int{"a": 1, "c": 3}},
+		{"add replaces and refreshes", 2, []op{{key: "a", value: 1}, {key: "b", value: 2}, {key: "a", value: 10}, {key: "c", value: 3}}, map[string]int{"a": 10, "c": 3}},
+		{"zero size stores nothing", 0, []op{{key: "a", value: 1}}, map[string]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := New[string, int](tt.size)
+			for _, op := range tt.ops {
+				if op.get {
+					cache.Get(op.key)
+				} else {
+					cache.Add(op.key, op.value)
+				}
+			}
+
+			for _, key := range []string{"a", "b", "c"} {
+				got, ok := cache.Get(key)
+				want, wantOK := tt.want[key]
+				if got != want || ok != wantOK {
+					t.Errorf("Get(%q) = %d, %v, want %d, %v", key, got, ok, want, wantOK)
+				}
+			}
+		})
+	}
+}
+
+func TestWeightedCache(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int64
+		values map[string]int
+		order  []string
+		want   []string
+	}{
+		{"fits", 10, map[string]int{"a": 4, "b": 6}, []string{"a", "b"}, []string{"a", "b"}},
+		{"evicts until it fits", 10, map[string]int{"a": 4, "b": 3, "c": 7}, []string{"a", "b", "c"}, []string{"b", "c"}},
+		{"evicts several", 10, map[string]int{"a": 4, "b": 3, "c": 9}, []string{"a", "b", "c"}, []string{"c"}},
+		{"too heavy", 10, map[string]int{"a": 4, "b": 11}, []string{"a", "b"}, []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewWeighted[string, int](tt.size, func(value int) int64 { return int64(value) })
+			for _, key := range tt.order {
+				cache.Add(key, tt.values[key])
+			}
+
+			kept := make(map[string]bool)
+			for _, key := range tt.want {
+				kept[key] = true
+			}
+
+			for _, key := range tt.order {
+				if _, ok := cache.Get(key); ok != kept[key] {
+					t.Errorf("Get(%q) found = %v, want %v", key, ok, kept[key])
+				}
+			}
+		})
+	}
+}
@@ -29,6 +29,9 @@ type Client interface {
 	QueryRow(ctx context.Context, query string, args ...any) pgx.Row
 	Get(ctx context.Context, dest interface{}, query string, args ...interface{}) error
 	Select(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Ping(ctx context.Context) error
+	Close()
 }
 
 type client struct {
@@ -77,3 +80,15 @@ func (c *client) Query(ctx context.Context, query string, args ...any) (pgx.Rows
 func (c *client) QueryRow(ctx context.Context, query string, args ...any) pgx.Row {
 	return c.pool.QueryRow(ctx, query, args...)
 }
+
+func (c *client) Begin(ctx context.Context) (pgx.Tx, error) {
+	return c.pool.Begin(ctx)
+}
+
+func (c *client) Ping(ctx context.Context) error {
+	return c.pool.Ping(ctx)
+}
+
+func (c *client) Close() {
+	c.pool.Close()
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Bucket is a token bucket per key: tokens are refilled at rate per second up to burst.
+type Bucket struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[int64]*bucketState
+	cleaned time.Time
+}
+
+type bucketState struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewBucket creates a bucket refilling rate tokens per second. The burst is the
+// rate rounded up, at least one token.
+func NewBucket(rate float64) *Bucket {
+	return &Bucket{
+		rate:    rate,
+		burst:   math.Max(1, math.Ceil(rate)),
+		buckets: make(map[int64]*bucketState),
+	}
+}
+
+// Reserve takes a token for the key and returns how long to wait before it may be used.
+// Tokens can be reserved ahead, so concurrent callers get increasing delays.
+func (bucket *Bucket) Reserve(key int64) time.Duration {
+	if bucket.rate <= 0 {
+		return 0
+	}
+
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(bucket.cleaned) > time.Minute {
+		bucket.cleanup(now)
+	}
+
+	state, ok := bucket.buckets[key]
+	if !ok {
+		state = &bucketState{tokens: bucket.burst, updated: now}
+		bucket.buckets[key] = state
+	}
+
+	state.tokens = math.Min(bucket.burst, state.tokens+now.Sub(state.updated).Seconds()*bucket.rate)
+	state.updated = now
+	state.tokens--
+
+	if state.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-state.tokens / bucket.rate * float64(time.Second))
+}
+
+// cleanup forgets keys whose buckets are full again.
+func (bucket *Bucket) cleanup(now time.Time) {
+	for key, state := range bucket.buckets {
+		if state.tokens+now.Sub(state.updated).Seconds()*bucket.rate >= bucket.burst {
+			delete(bucket.buckets, key)
+		}
+	}
+
+	bucket.cleaned = now
+}
@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketReserve(t *testing.T) {
+	tests := []struct {
+		name string
+		rate float64
+		want []time.Duration
+	}{
+		{"disabled", 0, []time.Duration{0, 0, 0}},
+		{"one per second", 1, []time.Duration{0, time.Second, 2 * time.Second}},
+		{"burst of two", 2, []time.Duration{0, 0, 500 * time.Millisecond, time.Second}},
+		{"one per three seconds", 1.0 / 3, []time.Duration{0, 3 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket := NewBucket(tt.rate)
+
+			for i, want := range tt.want {
+				got := bucket.Reserve(1)
+				if got < want-50*time.Millisecond || got > want {
+					t.Errorf("Reserve #%d = %s, want %s", i+1, got, want)
+				}
+			}
+
+			if got := bucket.Reserve(2); got != 0 {
+				t.Errorf("Reserve for another key = %s, want 0", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Window limits the number of events per key within a sliding period of time.
+type Window struct {
+	limit  int
+	period time.Duration
+
+	mu     sync.Mutex
+	events map[int64][]time.Time
+}
+
+func NewWindow(limit int, period time.Duration) *Window {
+	return &Window{
+		limit:  limit,
+		period: period,
+		events: make(map[int64][]time.Time),
+	}
+}
+
+// Allow records an event for the key if the limit lets it happen, otherwise it reports how long to wait.
+// Checking and recording under one lock keeps concurrent callers from exceeding the limit. The returned
+// time identifies the event for Release.
+func (window *Window) Allow(key int64) (at time.Time, ok bool, retryAfter time.Duration) {
+	now := time.Now()
+
+	if window.limit <= 0 {
+		return now, true, 0
+	}
+
+	window.mu.Lock()
+	defer window.mu.Unlock()
+
+	events := window.actual(key, now)
+	if len(events) >= window.limit {
+		return time.Time{}, false, events[0].Add(window.period).Sub(now)
+	}
+
+	window.events[key] = append(events, now)
+
+	return now, true, 0
+}
+
+// Release takes back the event recorded by Allow at the given time, e.g. when the allowed action failed.
+func (window *Window) Release(key int64, at time.Time) {
+	if window.limit <= 0 {
+		return
+	}
+
+	window.mu.Lock()
+	defer window.mu.Unlock()
+
+	events := window.events[key]
+	for i, event := range events {
+		if !event.Equal(at) {
+			continue
+		}
+
+		if len(events) == 1 {
+			delete(window.events, key)
+			return
+		}
+
+		window.events[key] = append(events[:i:i], events[i+1:]...)
+		return
+	}
+}
+
+func (window *Window) actual(key int64, now time.Time) []time.Time {
+	events := window.events[key]
+
+	i := 0
+	for i < len(events) && now.Sub(events[i]) >= window.period {
+		i++
+	}
+
+	events = events[i:]
+	if len(events) == 0 {
+		delete(window.events, key)
+		return nil
+	}
+
+	window.events[key] = events
+
+	return events
+}
@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowRelease(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		allow   int
+		release []int
+		want    bool
+	}{
+		{"under the limit", 2, 1, nil, true},
+		{"at the limit", 2, 2, nil, false},
+		{"released event", 2, 2, []int{0}, true},
+		{"released twice", 2, 2, []int{1, 1}, true},
+		{"disabled", 0, 5, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window := NewWindow(tt.limit, time.Hour)
+
+			var events []time.Time
+			for i := 0; i < tt.allow; i++ {
+				at, ok, _ := window.Allow(1)
+				if !ok {
+					t.Fatalf("Allow #%d is not allowed", i+1)
+				}
+				events = append(events, at)
+			}
+
+			for _, i := range tt.release {
+				window.Release(1, events[i])
+			}
+
+			if _, ok, _ := window.Allow(1); ok != tt.want {
+				t.Errorf("Allow = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowReleaseOwnEvent(t *testing.T) {
+	window := NewWindow(2, time.Hour)
+
+	first, _, _ := window.Allow(1)
+	window.Allow(1)
+
+	window.Release(1, first)
+
+	if len(window.events[1]) != 1 || window.events[1][0].Equal(first) {
+		t.Errorf("events after Release = %v, want the second event only", window.events[1])
+	}
+}